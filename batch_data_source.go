@@ -0,0 +1,138 @@
+package pipedream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchDataGetter looks up many keys in one call. Keys missing from the result are reported as ErrValueNotFound.
+type BatchDataGetter[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// BatchConfig configures how a batch data source aggregates calls.
+type BatchConfig struct {
+	// Window is how long to wait for more keys after the first key of a batch arrives.
+	Window time.Duration
+
+	// MaxBatchSize dispatches a batch as soon as it holds this many distinct keys.
+	// If not positive, batches are only limited by the window.
+	MaxBatchSize int
+
+	// Clock used for the window. Default SystemClock.
+	Clock Clock
+}
+
+// NewBatchDataSource creates a DataSource that takes a single key K and returns a single V,
+// but transparently aggregates concurrent calls into bulk calls to the getter.
+// Options such as WithTimeout and WithRetry apply to each bulk call.
+//
+// The bulk call is detached from the callers' contexts, since it is shared between them;
+// each caller stops waiting as soon as its own context is done.
+func NewBatchDataSource[K comparable, V any](
+	name string,
+	getter BatchDataGetter[K, V],
+	config BatchConfig,
+	opts ...DataSourceOption,
+) DataSource {
+	if config.Clock == nil {
+		config.Clock = SystemClock{}
+	}
+
+	bulkConfig := newDataSourceConfig(opts)
+	loader := &batchLoader[K, V]{
+		config: config,
+		call: bulkConfig.wrap(func(ctx context.Context, params any) (any, error) {
+			return getter(ctx, params.([]K))
+		}),
+	}
+
+	d := NewDataSource(name, loader.load)
+	d.wrappers = append([]string{"Batch"}, bulkConfig.names()...)
+	d.healthChecks = bulkConfig.healthChecks
+	return d
+}
+
+type batchLoader[K comparable, V any] struct {
+	config BatchConfig
+	call   GetFunc
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	ctx  context.Context
+	keys []K
+	seen map[K]struct{}
+
+	done    chan struct{}
+	results map[K]V
+	err     error
+}
+
+func (l *batchLoader[K, V]) load(ctx context.Context, key K) (V, error) {
+	var zeroV V
+
+	l.mu.Lock()
+	b := l.pending
+	if b == nil {
+		b = &batch[K, V]{
+			ctx:  context.WithoutCancel(ctx),
+			seen: map[K]struct{}{},
+			done: make(chan struct{}),
+		}
+		l.pending = b
+		go l.dispatchAfterWindow(b)
+	}
+	if _, ok := b.seen[key]; !ok {
+		b.seen[key] = struct{}{}
+		b.keys = append(b.keys, key)
+	}
+	if l.config.MaxBatchSize > 0 && len(b.keys) >= l.config.MaxBatchSize {
+		l.pending = nil
+		go l.dispatch(b)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return zeroV, ctx.Err()
+	}
+
+	if b.err != nil {
+		return zeroV, b.err
+	}
+	v, ok := b.results[key]
+	if !ok {
+		return zeroV, fmt.Errorf("%w: %v", ErrValueNotFound, key)
+	}
+	return v, nil
+}
+
+func (l *batchLoader[K, V]) dispatchAfterWindow(b *batch[K, V]) {
+	<-l.config.Clock.After(l.config.Window)
+
+	l.mu.Lock()
+	if l.pending != b {
+		// Already dispatched because it filled up.
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.dispatch(b)
+}
+
+func (l *batchLoader[K, V]) dispatch(b *batch[K, V]) {
+	defer close(b.done)
+
+	resp, err := l.call(b.ctx, b.keys)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.results, _ = resp.(map[K]V)
+}
@@ -0,0 +1,180 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingBatchGetter doubles each key except those in missing, and records the keys of every bulk call.
+type recordingBatchGetter struct {
+	missing map[int]bool
+
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (g *recordingBatchGetter) get(ctx context.Context, keys []int) (map[int]int, error) {
+	g.mu.Lock()
+	g.batches = append(g.batches, slices.Clone(keys))
+	g.mu.Unlock()
+
+	resp := map[int]int{}
+	for _, k := range keys {
+		if !g.missing[k] {
+			resp[k] = k * 2
+		}
+	}
+	return resp, nil
+}
+
+func (g *recordingBatchGetter) calls() [][]int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.batches)
+}
+
+func newTestBatchLoader(getter BatchDataGetter[int, int], config BatchConfig) *batchLoader[int, int] {
+	return &batchLoader[int, int]{
+		config: config,
+		call: func(ctx context.Context, params any) (any, error) {
+			return getter(ctx, params.([]int))
+		},
+	}
+}
+
+// pendingKeys returns the keys of the batch waiting to be dispatched.
+func (l *batchLoader[K, V]) pendingKeys() []K {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending == nil {
+		return nil
+	}
+	return slices.Clone(l.pending.keys)
+}
+
+type batchResult struct {
+	key int
+	v   int
+	err error
+}
+
+// loadAsync loads each key in its own goroutine and sends the results on the returned channel.
+func loadAsync(ctx context.Context, l *batchLoader[int, int], keys ...int) <-chan batchResult {
+	results := make(chan batchResult, len(keys))
+	for _, k := range keys {
+		go func() {
+			v, err := l.load(ctx, k)
+			results <- batchResult{key: k, v: v, err: err}
+		}()
+	}
+	return results
+}
+
+func TestBatchMergesCallsInWindow(t *testing.T) {
+	clock := &fakeClock{timers: make(chan chan time.Time)}
+	getter := &recordingBatchGetter{missing: map[int]bool{3: true}}
+	l := newTestBatchLoader(getter.get, BatchConfig{Window: time.Millisecond, Clock: clock})
+
+	results := loadAsync(context.Background(), l, 1, 2, 2, 3)
+	timer := <-clock.timers
+	waitFor(t, func() bool { return len(l.pendingKeys()) == 3 })
+	if calls := getter.calls(); len(calls) != 0 {
+		t.Fatalf("bulk calls %v before the window ended, want none", calls)
+	}
+	timer <- time.Time{}
+
+	for range 4 {
+		r := <-results
+		if r.key == 3 {
+			if !errors.Is(r.err, ErrValueNotFound) {
+				t.Errorf("missing key got %v, %v, want ErrValueNotFound", r.v, r.err)
+			}
+			continue
+		}
+		if r.err != nil || r.v != r.key*2 {
+			t.Errorf("key %d got %v, %v, want %d, nil", r.key, r.v, r.err, r.key*2)
+		}
+	}
+
+	calls := getter.calls()
+	if len(calls) != 1 {
+		t.Fatalf("bulk calls = %v, want 1", calls)
+	}
+	slices.Sort(calls[0])
+	if !reflect.DeepEqual(calls[0], []int{1, 2, 3}) {
+		t.Errorf("bulk call keys = %v, want [1 2 3] without duplicates", calls[0])
+	}
+}
+
+func TestBatchMaxBatchSize(t *testing.T) {
+	clock := &fakeClock{timers: make(chan chan time.Time)}
+	getter := &recordingBatchGetter{}
+	l := newTestBatchLoader(getter.get, BatchConfig{Window: time.Millisecond, MaxBatchSize: 2, Clock: clock})
+
+	first := loadAsync(context.Background(), l, 1)
+	firstTimer := <-clock.timers
+	waitFor(t, func() bool { return len(l.pendingKeys()) == 1 })
+
+	// The second key fills the batch, which is dispatched without waiting for the window.
+	second := loadAsync(context.Background(), l, 2)
+	for _, results := range []<-chan batchResult{first, second} {
+		if r := <-results; r.err != nil || r.v != r.key*2 {
+			t.Errorf("key %d got %v, %v, want %d, nil", r.key, r.v, r.err, r.key*2)
+		}
+	}
+	firstTimer <- time.Time{}
+
+	third := loadAsync(context.Background(), l, 3)
+	thirdTimer := <-clock.timers
+	waitFor(t, func() bool { return len(l.pendingKeys()) == 1 })
+	thirdTimer <- time.Time{}
+	if r := <-third; r.err != nil || r.v != 6 {
+		t.Errorf("key 3 got %v, %v, want 6, nil", r.v, r.err)
+	}
+
+	calls := getter.calls()
+	slices.Sort(calls[0])
+	if want := [][]int{{1, 2}, {3}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("bulk calls = %v, want %v", calls, want)
+	}
+}
+
+func TestBatchErrorAndCancel(t *testing.T) {
+	clock := &fakeClock{timers: make(chan chan time.Time)}
+	l := newTestBatchLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		return nil, errNode
+	}, BatchConfig{Window: time.Millisecond, Clock: clock})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := loadAsync(ctx, l, 1)
+	timer := <-clock.timers
+	waiting := loadAsync(context.Background(), l, 2)
+	waitFor(t, func() bool { return len(l.pendingKeys()) == 2 })
+
+	// A caller giving up does not stop the batch for the others.
+	cancel()
+	if r := <-canceled; !errors.Is(r.err, context.Canceled) {
+		t.Errorf("canceled caller got %v, want context.Canceled", r.err)
+	}
+	timer <- time.Time{}
+	if r := <-waiting; !errors.Is(r.err, errNode) {
+		t.Errorf("got %v, want the bulk call's error", r.err)
+	}
+}
+
+func TestNewBatchDataSource(t *testing.T) {
+	getter := &recordingBatchGetter{}
+	source := NewBatchDataSource("double", getter.get, BatchConfig{Clock: &fakeClock{}}, WithTimeout(time.Second))
+
+	if v, err := source.Get(context.Background(), 4); err != nil || v != 8 {
+		t.Errorf("Get = %v, %v, want 8, nil", v, err)
+	}
+	if want := []string{"Batch", "Timeout(1s)"}; !reflect.DeepEqual(source.Wrappers(), want) {
+		t.Errorf("Wrappers = %v, want %v", source.Wrappers(), want)
+	}
+}
@@ -0,0 +1,83 @@
+package pipedream
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+var ErrBulkheadFull = fmt.Errorf("bulkhead is full")
+
+// BulkheadConfig configures a Bulkhead.
+type BulkheadConfig struct {
+	// MaxConcurrent is the maximum number of calls in flight at once. Must be positive.
+	MaxConcurrent int
+
+	// MaxWaiting is the maximum number of calls that may queue for a free slot.
+	// If 0, calls are rejected with ErrBulkheadFull as soon as all slots are taken.
+	// Waiting calls give up when their context is done.
+	MaxWaiting int
+}
+
+// Bulkhead is a data source Middleware that limits the number of concurrent calls to a dependency,
+// so a slow dependency cannot tie up every pipeline.
+type Bulkhead struct {
+	config BulkheadConfig
+
+	slots   chan struct{}
+	waiting atomic.Int64
+}
+
+func NewBulkhead(config BulkheadConfig) *Bulkhead {
+	return &Bulkhead{
+		config: config,
+		slots:  make(chan struct{}, max(config.MaxConcurrent, 1)),
+	}
+}
+
+// Wrap implements the Middleware interface for Bulkhead.
+func (b *Bulkhead) Wrap(next GetFunc) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		if err := b.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer func() { <-b.slots }()
+
+		return next(ctx, params)
+	}
+}
+
+func (b *Bulkhead) String() string {
+	return fmt.Sprintf("Bulkhead(%d)", b.config.MaxConcurrent)
+}
+
+// InFlight returns the number of calls currently running.
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+// Waiting returns the number of calls currently queued for a slot.
+func (b *Bulkhead) Waiting() int {
+	return int(b.waiting.Load())
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if b.waiting.Add(1) > int64(b.config.MaxWaiting) {
+		b.waiting.Add(-1)
+		return ErrBulkheadFull
+	}
+	defer b.waiting.Add(-1)
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
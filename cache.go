@@ -0,0 +1,303 @@
+package pipedream
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrUnhashableParams = fmt.Errorf("params cannot be hashed into a cache key")
+
+// maxHashDepth bounds how deeply nested params may be, which also guards against cyclic pointers.
+const maxHashDepth = 64
+
+// CacheEntry is a cached data source response.
+type CacheEntry struct {
+	Value any
+	Err   error
+
+	// ExpiresAt is when the entry expires. The zero time means it never does.
+	ExpiresAt time.Time
+}
+
+// CacheStore is the backend a Cache keeps its entries in.
+// Implementations must be safe for concurrent use. Expiry is checked by the Cache,
+// but stores may also use ExpiresAt to evict entries early.
+type CacheStore interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+	Delete(key string)
+}
+
+// CacheKeyFunc derives a cache key from data source params.
+type CacheKeyFunc func(params any) (string, error)
+
+// CacheConfig configures a Cache. Zero values are replaced with the documented defaults.
+type CacheConfig struct {
+	// Store to keep entries in. Default is a MemoryCacheStore holding up to 1000 entries.
+	// A store should only be shared between data sources if the KeyFunc keeps their keys apart.
+	Store CacheStore
+
+	// KeyFunc derives the cache key from params. Default HashParams.
+	KeyFunc CacheKeyFunc
+
+	// TTL is how long successful responses are cached for. If it is not positive, they never expire,
+	// and are only removed by Invalidate or by the store evicting them.
+	TTL time.Duration
+
+	// CacheError decides which errors are cached. By default errors are not cached.
+	CacheError func(err error) bool
+
+	// NegativeTTL is how long errors accepted by CacheError are cached for. Default TTL.
+	NegativeTTL time.Duration
+
+	// Clock used for expiry. Default SystemClock.
+	Clock Clock
+}
+
+// Cache is a data source Middleware that caches responses by params.
+type Cache struct {
+	config CacheConfig
+}
+
+func NewCache(config CacheConfig) *Cache {
+	if config.Store == nil {
+		config.Store = NewMemoryCacheStore(1000)
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = HashParams
+	}
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = config.TTL
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock{}
+	}
+
+	return &Cache{config: config}
+}
+
+// Wrap implements the Middleware interface for Cache.
+// If a key cannot be derived from the params, the call goes through uncached.
+func (c *Cache) Wrap(next GetFunc) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		key, err := c.config.KeyFunc(params)
+		if err != nil {
+			return next(ctx, params)
+		}
+
+		now := c.config.Clock.Now()
+		if entry, ok := c.config.Store.Get(key); ok {
+			if entry.ExpiresAt.IsZero() || now.Before(entry.ExpiresAt) {
+				return entry.Value, entry.Err
+			}
+			c.config.Store.Delete(key)
+		}
+
+		resp, err := next(ctx, params)
+		switch {
+		case err == nil:
+			c.config.Store.Set(key, CacheEntry{Value: resp, ExpiresAt: expiresAt(now, c.config.TTL)})
+		case c.config.CacheError != nil && c.config.CacheError(err):
+			c.config.Store.Set(key, CacheEntry{Value: resp, Err: err, ExpiresAt: expiresAt(now, c.config.NegativeTTL)})
+		}
+		return resp, err
+	}
+}
+
+// expiresAt returns when an entry cached now for the ttl expires, or the zero time if it never does.
+func expiresAt(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func (c *Cache) String() string {
+	return fmt.Sprintf("Cache(%s)", c.config.TTL)
+}
+
+// Invalidate removes the cached response for the params, if any.
+func (c *Cache) Invalidate(params any) error {
+	key, err := c.config.KeyFunc(params)
+	if err != nil {
+		return err
+	}
+	c.config.Store.Delete(key)
+	return nil
+}
+
+// MemoryCacheStore is an in-memory CacheStore that evicts the least recently used entry once full.
+type MemoryCacheStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List // Most recently used at the front.
+	entries map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCacheStore creates a MemoryCacheStore. If maxEntries is not positive, the store is unbounded.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+func (m *MemoryCacheStore) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	m.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).entry, true
+}
+
+func (m *MemoryCacheStore) Set(key string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(elem)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+func (m *MemoryCacheStore) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+	}
+}
+
+// Len returns the number of entries in the store, including expired entries not yet removed.
+func (m *MemoryCacheStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+// HashParams derives a deterministic key from the params by hashing their type and contents.
+// Pointers are followed and maps are hashed independently of iteration order.
+// Params containing channels, functions or unsafe pointers cannot be hashed.
+func HashParams(params any) (string, error) {
+	h := sha256.New()
+	if err := hashValue(h, reflect.ValueOf(params), 0); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashValue(h hash.Hash, v reflect.Value, depth int) error {
+	if depth > maxHashDepth {
+		return fmt.Errorf("%w: nested too deeply or cyclic", ErrUnhashableParams)
+	}
+	if !v.IsValid() {
+		hashString(h, "nil")
+		return nil
+	}
+	hashString(h, v.Type().String())
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			hashUint(h, 1)
+		} else {
+			hashUint(h, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hashUint(h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		hashUint(h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		hashUint(h, math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		hashUint(h, math.Float64bits(real(c)))
+		hashUint(h, math.Float64bits(imag(c)))
+	case reflect.String:
+		hashString(h, v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			hashString(h, "nil")
+			return nil
+		}
+		return hashValue(h, v.Elem(), depth+1)
+	case reflect.Array, reflect.Slice:
+		hashUint(h, uint64(v.Len()))
+		for i := range v.Len() {
+			if err := hashValue(h, v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Hash each entry separately and sort the results, so iteration order does not matter.
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			eh := sha256.New()
+			if err := hashValue(eh, iter.Key(), depth+1); err != nil {
+				return err
+			}
+			if err := hashValue(eh, iter.Value(), depth+1); err != nil {
+				return err
+			}
+			entries = append(entries, string(eh.Sum(nil)))
+		}
+		slices.Sort(entries)
+		hashUint(h, uint64(len(entries)))
+		for _, e := range entries {
+			hashString(h, e)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			hashString(h, v.Type().Field(i).Name)
+			if err := hashValue(h, v.Field(i), depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unsupported kind %s", ErrUnhashableParams, v.Kind())
+	}
+	return nil
+}
+
+func hashUint(h hash.Hash, u uint64) {
+	h.Write(binary.BigEndian.AppendUint64(nil, u))
+}
+
+// hashString writes a length-prefixed string, so adjacent strings cannot run into each other.
+func hashString(h hash.Hash, s string) {
+	hashUint(h, uint64(len(s)))
+	h.Write([]byte(s))
+}
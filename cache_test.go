@@ -0,0 +1,194 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// countingGetter returns a GetFunc that doubles int params, counting its calls.
+// It fails with errNode for negative params.
+func countingGetter(calls *int) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		*calls++
+		n := params.(int)
+		if n < 0 {
+			return nil, errNode
+		}
+		return n * 2, nil
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	clock := &fakeClock{}
+	var calls int
+	get := NewCache(CacheConfig{TTL: time.Minute, Clock: clock}).Wrap(countingGetter(&calls))
+
+	for range 2 {
+		if v, err := get(context.Background(), 2); err != nil || v != 4 {
+			t.Fatalf("Get = %v, %v, want 4, nil", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("getter called %d times before expiry, want 1", calls)
+	}
+
+	clock.Advance(time.Minute - time.Nanosecond)
+	get(context.Background(), 2)
+	clock.Advance(time.Nanosecond)
+	get(context.Background(), 2)
+	if calls != 2 {
+		t.Errorf("getter called %d times after expiry, want 2", calls)
+	}
+}
+
+func TestCacheZeroTTLNeverExpires(t *testing.T) {
+	clock := &fakeClock{}
+	var calls int
+	cache := NewCache(CacheConfig{Clock: clock})
+	get := cache.Wrap(countingGetter(&calls))
+
+	get(context.Background(), 2)
+	clock.Advance(1000 * time.Hour)
+	get(context.Background(), 2)
+	if calls != 1 {
+		t.Errorf("getter called %d times, want 1", calls)
+	}
+
+	if err := cache.Invalidate(2); err != nil {
+		t.Fatalf("Invalidate returned error: %v", err)
+	}
+	get(context.Background(), 2)
+	if calls != 2 {
+		t.Errorf("getter called %d times after Invalidate, want 2", calls)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+	var calls int
+	get := NewCache(CacheConfig{Store: store, TTL: time.Hour}).Wrap(countingGetter(&calls))
+
+	get(context.Background(), 1)
+	get(context.Background(), 2)
+	get(context.Background(), 1) // 1 is now more recently used than 2.
+	get(context.Background(), 3) // Evicts 2.
+	if store.Len() != 2 || calls != 3 {
+		t.Fatalf("Len = %d after %d calls, want 2 after 3", store.Len(), calls)
+	}
+
+	get(context.Background(), 1)
+	if calls != 3 {
+		t.Errorf("1 was evicted, want it kept")
+	}
+	get(context.Background(), 2)
+	if calls != 4 {
+		t.Errorf("2 was kept, want it evicted")
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	clock := &fakeClock{}
+	var calls int
+	get := NewCache(CacheConfig{
+		TTL:         time.Hour,
+		NegativeTTL: time.Second,
+		CacheError:  func(err error) bool { return errors.Is(err, errNode) },
+		Clock:       clock,
+	}).Wrap(countingGetter(&calls))
+
+	for range 2 {
+		if _, err := get(context.Background(), -1); !errors.Is(err, errNode) {
+			t.Fatalf("got %v, want errNode", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("getter called %d times, want the error cached", calls)
+	}
+	clock.Advance(time.Second)
+	get(context.Background(), -1)
+	if calls != 2 {
+		t.Errorf("getter called %d times, want the error expired after NegativeTTL", calls)
+	}
+
+	// Errors are not cached unless CacheError accepts them.
+	calls = 0
+	uncached := NewCache(CacheConfig{TTL: time.Hour}).Wrap(countingGetter(&calls))
+	uncached(context.Background(), -1)
+	uncached(context.Background(), -1)
+	if calls != 2 {
+		t.Errorf("getter called %d times, want errors not cached", calls)
+	}
+}
+
+func TestCacheKeyFunc(t *testing.T) {
+	var calls int
+	get := NewCache(CacheConfig{
+		TTL: time.Hour,
+		KeyFunc: func(params any) (string, error) {
+			n := params.(int)
+			if n == 0 {
+				return "", ErrUnhashableParams
+			}
+			return fmt.Sprint(n % 10), nil
+		},
+	}).Wrap(countingGetter(&calls))
+
+	get(context.Background(), 1)
+	if v, _ := get(context.Background(), 11); v != 2 || calls != 1 {
+		t.Errorf("Get(11) = %v after %d calls, want the cached response for 1", v, calls)
+	}
+
+	// Params without a key are not cached.
+	get(context.Background(), 0)
+	get(context.Background(), 0)
+	if calls != 3 {
+		t.Errorf("getter called %d times, want 3", calls)
+	}
+}
+
+func TestHashParams(t *testing.T) {
+	type params struct {
+		IDs  []int
+		Tags map[string]int
+	}
+
+	a, b := map[string]int{}, map[string]int{}
+	for i := range 100 {
+		a[fmt.Sprint(i)] = i
+		b[fmt.Sprint(99-i)] = 99 - i
+	}
+	ka, err := HashParams(params{IDs: []int{1}, Tags: a})
+	if err != nil {
+		t.Fatalf("HashParams returned error: %v", err)
+	}
+	kb, _ := HashParams(params{IDs: []int{1}, Tags: b})
+	if ka != kb {
+		t.Error("equal params hashed differently")
+	}
+
+	distinct := []any{1, int64(1), "1", []int{1}, []int{1, 0}, params{}, struct{ X int }{}, struct{ Y int }{}, nil}
+	seen := map[string]any{}
+	for _, p := range distinct {
+		k, err := HashParams(p)
+		if err != nil {
+			t.Fatalf("HashParams(%#v) returned error: %v", p, err)
+		}
+		if other, ok := seen[k]; ok {
+			t.Errorf("HashParams(%#v) = HashParams(%#v)", p, other)
+		}
+		seen[k] = p
+	}
+
+	if _, err := HashParams(func() {}); !errors.Is(err, ErrUnhashableParams) {
+		t.Errorf("HashParams(func): got %v, want ErrUnhashableParams", err)
+	}
+	type node struct{ Next *node }
+	cyclic := &node{}
+	cyclic.Next = cyclic
+	if _, err := HashParams(cyclic); !errors.Is(err, ErrUnhashableParams) {
+		t.Errorf("HashParams(cyclic): got %v, want ErrUnhashableParams", err)
+	}
+}
@@ -0,0 +1,281 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = fmt.Errorf("circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // Calls pass through.
+	CircuitOpen                         // Calls are rejected with ErrCircuitOpen.
+	CircuitHalfOpen                     // A limited number of trial calls pass through.
+)
+
+// Returns a string representation of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "Closed"
+	case CircuitOpen:
+		return "Open"
+	case CircuitHalfOpen:
+		return "HalfOpen"
+	default:
+		return fmt.Sprintf("Unknown(%d)", s)
+	}
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker. Zero values are replaced with the documented defaults.
+type CircuitBreakerConfig struct {
+	// WindowSize is the number of most recent calls used to compute the failure ratio.
+	// The circuit does not open until the window is full. Default 10.
+	WindowSize int
+
+	// FailureRatio is the ratio of failed calls in the window at which the circuit opens. Default 0.5.
+	FailureRatio float64
+
+	// CoolDown is how long the circuit stays open before allowing trial calls. Default 30 seconds.
+	CoolDown time.Duration
+
+	// HalfOpenCalls is the number of trial calls allowed while half-open.
+	// The circuit closes once all of them succeed, and opens again on any failure. Default 1.
+	HalfOpenCalls int
+
+	// IsFailure decides whether an error counts as a failure. It is only called for calls that returned an error.
+	// Default is any error other than the caller cancelling its context.
+	IsFailure func(err error) bool
+
+	// Clock used for the cool-down. Default SystemClock.
+	Clock Clock
+}
+
+// CircuitBreakerStats is a snapshot of a CircuitBreaker's state.
+type CircuitBreakerStats struct {
+	State CircuitState
+
+	// Calls and failures in the current window while closed.
+	Calls    int
+	Failures int
+
+	// OpenedAt is when the circuit last opened.
+	OpenedAt time.Time
+}
+
+// CircuitBreaker is a data source Middleware that stops calling a failing dependency for a while,
+// failing fast with ErrCircuitOpen instead.
+// A single CircuitBreaker tracks one dependency and should not be shared between unrelated data sources.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+
+	mu               sync.Mutex
+	state            CircuitState
+	window           []bool // Ring buffer of outcomes, true meaning failure.
+	next             int
+	calls            int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenSuccess  int
+
+	// generation changes with every change of state, so that outcomes of calls admitted before it are ignored.
+	generation int
+}
+
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.WindowSize <= 0 {
+		config.WindowSize = 10
+	}
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = 0.5
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+	if config.HalfOpenCalls <= 0 {
+		config.HalfOpenCalls = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock{}
+	}
+
+	return &CircuitBreaker{
+		config: config,
+		window: make([]bool, config.WindowSize),
+	}
+}
+
+// Wrap implements the Middleware interface for CircuitBreaker.
+func (cb *CircuitBreaker) Wrap(next GetFunc) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		generation, err := cb.allow()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next(ctx, params)
+		cb.record(generation, err != nil && cb.config.IsFailure(err))
+		return resp, err
+	}
+}
+
+func (cb *CircuitBreaker) String() string {
+	return "CircuitBreaker"
+}
+
+// CheckHealth implements the HealthChecker interface for CircuitBreaker. An open circuit is unhealthy.
+func (cb *CircuitBreaker) CheckHealth(ctx context.Context) error {
+	if cb.State() == CircuitOpen {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	return cb.Stats().State
+}
+
+// Stats returns a snapshot of the circuit breaker.
+func (cb *CircuitBreaker) Stats() CircuitBreakerStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.checkCoolDown()
+	return CircuitBreakerStats{
+		State:    cb.state,
+		Calls:    cb.calls,
+		Failures: cb.failures,
+		OpenedAt: cb.openedAt,
+	}
+}
+
+// Reset closes the circuit and clears its history.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.close()
+}
+
+// allow admits a call, returning the generation it was admitted under.
+func (cb *CircuitBreaker) allow() (int, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.checkCoolDown()
+	switch cb.state {
+	case CircuitOpen:
+		return 0, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.halfOpenInFlight+cb.halfOpenSuccess >= cb.config.HalfOpenCalls {
+			return 0, ErrCircuitOpen
+		}
+		cb.halfOpenInFlight++
+	}
+	return cb.generation, nil
+}
+
+// record records the outcome of a call admitted under the given generation.
+func (cb *CircuitBreaker) record(generation int, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		// The call was admitted in an earlier state; its outcome no longer matters.
+		return
+	}
+	switch cb.state {
+	case CircuitClosed:
+		if cb.calls == len(cb.window) {
+			// Window is full, so the oldest outcome drops out.
+			if cb.window[cb.next] {
+				cb.failures--
+			}
+		} else {
+			cb.calls++
+		}
+		cb.window[cb.next] = failed
+		cb.next = (cb.next + 1) % len(cb.window)
+		if failed {
+			cb.failures++
+		}
+
+		if cb.calls == len(cb.window) && float64(cb.failures)/float64(cb.calls) >= cb.config.FailureRatio {
+			cb.open()
+		}
+	case CircuitHalfOpen:
+		cb.halfOpenInFlight--
+		if failed {
+			cb.open()
+			return
+		}
+		cb.halfOpenSuccess++
+		if cb.halfOpenSuccess >= cb.config.HalfOpenCalls {
+			cb.close()
+		}
+	}
+}
+
+// checkCoolDown moves an open circuit to half-open once the cool-down has elapsed. Must hold cb.mu.
+func (cb *CircuitBreaker) checkCoolDown() {
+	if cb.state == CircuitOpen && cb.config.Clock.Now().Sub(cb.openedAt) >= cb.config.CoolDown {
+		cb.state = CircuitHalfOpen
+		cb.generation++
+		cb.halfOpenInFlight = 0
+		cb.halfOpenSuccess = 0
+	}
+}
+
+// open must hold cb.mu.
+func (cb *CircuitBreaker) open() {
+	cb.state = CircuitOpen
+	cb.generation++
+	cb.openedAt = cb.config.Clock.Now()
+}
+
+// close must hold cb.mu.
+func (cb *CircuitBreaker) close() {
+	cb.state = CircuitClosed
+	cb.generation++
+	clear(cb.window)
+	cb.next = 0
+	cb.calls = 0
+	cb.failures = 0
+}
+
+// CircuitStateValue builds the current CircuitState of a CircuitBreaker, so conditions can react to it.
+// For example, a BranchNode can skip a query while the circuit is open:
+//
+//	&ValueCondition{
+//		LHS:     CircuitStateValue{Breaker: breaker},
+//		RHS:     LiteralValue[CircuitState]{Value: CircuitOpen},
+//		Operand: ConditionEqual,
+//	}
+type CircuitStateValue struct {
+	Breaker *CircuitBreaker
+}
+
+func (c CircuitStateValue) Build(pctx PipelineContext) (any, error) {
+	if c.Breaker == nil {
+		return nil, fmt.Errorf("%w: no circuit breaker", ErrNilValueBuilder)
+	}
+	return c.Breaker.State(), nil
+}
+
+// BuildType implements the TypedValueBuilder interface for CircuitStateValue.
+func (c CircuitStateValue) BuildType(vctx ValidationContext) (reflect.Type, error) {
+	return reflect.TypeFor[CircuitState](), nil
+}
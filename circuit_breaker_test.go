@@ -0,0 +1,275 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerIsFailureOnlySeesErrors(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		WindowSize: 2,
+		IsFailure: func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		},
+	})
+	source := NewDataSource("double", double).WithMiddleware(cb)
+
+	for range 2 {
+		if _, err := source.Get(context.Background(), 1); err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+	}
+	if state := cb.State(); state != CircuitClosed {
+		t.Errorf("State after successful calls = %v, want %v", state, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerOpensOnFailures(t *testing.T) {
+	boom := errors.New("boom")
+	cb := NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 2})
+	source := NewDataSource("failing", func(ctx context.Context, params int) (int, error) {
+		return 0, boom
+	}).WithMiddleware(cb)
+
+	for range 2 {
+		if _, err := source.Get(context.Background(), 1); !errors.Is(err, boom) {
+			t.Fatalf("Get: got %v, want %v", err, boom)
+		}
+	}
+	if state := cb.State(); state != CircuitOpen {
+		t.Errorf("State after failed calls = %v, want %v", state, CircuitOpen)
+	}
+	if _, err := source.Get(context.Background(), 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get with open circuit: got %v, want ErrCircuitOpen", err)
+	}
+}
+
+// switchGetter returns a GetFunc that fails while *fail is set, and otherwise succeeds.
+func switchGetter(fail *bool) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		if *fail {
+			return nil, errNode
+		}
+		return params, nil
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	clock := &fakeClock{}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 2, CoolDown: 10 * time.Second, HalfOpenCalls: 2, Clock: clock})
+	fail := true
+	get := cb.Wrap(switchGetter(&fail))
+
+	get(context.Background(), 1)
+	get(context.Background(), 1)
+	if stats := cb.Stats(); stats.State != CircuitOpen || !stats.OpenedAt.Equal(clock.Now()) {
+		t.Fatalf("Stats = %+v, want open now", stats)
+	}
+
+	clock.Advance(9 * time.Second)
+	if _, err := get(context.Background(), 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get during cool-down: got %v, want ErrCircuitOpen", err)
+	}
+	clock.Advance(time.Second)
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Fatalf("State after cool-down = %v, want %v", state, CircuitHalfOpen)
+	}
+
+	// A failed trial opens the circuit again, restarting the cool-down.
+	if _, err := get(context.Background(), 1); !errors.Is(err, errNode) {
+		t.Errorf("trial call: got %v, want errNode", err)
+	}
+	if stats := cb.Stats(); stats.State != CircuitOpen || !stats.OpenedAt.Equal(clock.Now()) {
+		t.Fatalf("Stats after failed trial = %+v, want open now", stats)
+	}
+
+	// All trials must succeed to close it.
+	clock.Advance(10 * time.Second)
+	fail = false
+	if _, err := get(context.Background(), 1); err != nil {
+		t.Fatalf("trial call: got %v, want nil", err)
+	}
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Errorf("State after one of two trials = %v, want %v", state, CircuitHalfOpen)
+	}
+	get(context.Background(), 1)
+	if state := cb.State(); state != CircuitClosed {
+		t.Errorf("State after both trials = %v, want %v", state, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerLimitsTrialCalls(t *testing.T) {
+	clock := &fakeClock{}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 1, CoolDown: time.Second, Clock: clock})
+	fail := true
+	cb.Wrap(switchGetter(&fail))(context.Background(), 1)
+	clock.Advance(time.Second)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	trial := cb.Wrap(func(ctx context.Context, params any) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		trial(context.Background(), 1)
+	}()
+	<-started
+
+	if _, err := cb.Wrap(switchGetter(&fail))(context.Background(), 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second call while half-open: got %v, want ErrCircuitOpen", err)
+	}
+	close(release)
+	<-done
+	if state := cb.State(); state != CircuitClosed {
+		t.Errorf("State after trial = %v, want %v", state, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleCalls(t *testing.T) {
+	clock := &fakeClock{}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 2, CoolDown: time.Second, Clock: clock})
+
+	// A call admitted while closed, which finishes only once the circuit is half-open.
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := cb.Wrap(func(ctx context.Context, params any) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		slow(context.Background(), 1)
+	}()
+	<-started
+
+	fail := true
+	get := cb.Wrap(switchGetter(&fail))
+	get(context.Background(), 1)
+	get(context.Background(), 1)
+	clock.Advance(time.Second)
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Fatalf("State = %v, want %v", state, CircuitHalfOpen)
+	}
+
+	close(release)
+	<-done
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Errorf("State after stale success = %v, want still %v", state, CircuitHalfOpen)
+	}
+	fail = false
+	if _, err := get(context.Background(), 1); err != nil {
+		t.Errorf("trial call: got %v, want nil", err)
+	}
+	if state := cb.State(); state != CircuitClosed {
+		t.Errorf("State after trial = %v, want %v", state, CircuitClosed)
+	}
+}
+
+// blockingCall starts a call through the bulkhead that holds its slot until release is closed.
+func blockingCall(t *testing.T, b *Bulkhead, release chan struct{}) <-chan error {
+	t.Helper()
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	get := b.Wrap(func(ctx context.Context, params any) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	go func() {
+		_, err := get(context.Background(), nil)
+		done <- err
+	}()
+	<-started
+	return done
+}
+
+// waitFor polls until cond holds, failing the test if it does not within a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBulkheadRejectsWhenFull(t *testing.T) {
+	b := NewBulkhead(BulkheadConfig{MaxConcurrent: 1})
+	release := make(chan struct{})
+	done := blockingCall(t, b, release)
+
+	get := b.Wrap(switchGetter(new(bool)))
+	if _, err := get(context.Background(), 1); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("got %v, want ErrBulkheadFull", err)
+	}
+	if b.InFlight() != 1 {
+		t.Errorf("InFlight = %d, want 1", b.InFlight())
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("blocking call: %v", err)
+	}
+	if _, err := get(context.Background(), 1); err != nil {
+		t.Errorf("call after the slot was freed: got %v, want nil", err)
+	}
+}
+
+func TestBulkheadQueues(t *testing.T) {
+	b := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxWaiting: 1})
+	release := make(chan struct{})
+	first := blockingCall(t, b, release)
+
+	get := b.Wrap(switchGetter(new(bool)))
+	queued := make(chan error, 1)
+	go func() {
+		_, err := get(context.Background(), 1)
+		queued <- err
+	}()
+	waitFor(t, func() bool { return b.Waiting() == 1 })
+
+	if _, err := get(context.Background(), 1); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("call with a full queue: got %v, want ErrBulkheadFull", err)
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Errorf("first call: %v", err)
+	}
+	if err := <-queued; err != nil {
+		t.Errorf("queued call: got %v, want nil", err)
+	}
+	if b.InFlight() != 0 || b.Waiting() != 0 {
+		t.Errorf("InFlight = %d, Waiting = %d, want 0, 0", b.InFlight(), b.Waiting())
+	}
+}
+
+func TestBulkheadWaitingRespectsContext(t *testing.T) {
+	b := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxWaiting: 1})
+	release := make(chan struct{})
+	defer close(release)
+	blockingCall(t, b, release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan error, 1)
+	go func() {
+		_, err := b.Wrap(switchGetter(new(bool)))(ctx, 1)
+		queued <- err
+	}()
+	waitFor(t, func() bool { return b.Waiting() == 1 })
+	cancel()
+
+	if err := <-queued; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if b.Waiting() != 0 {
+		t.Errorf("Waiting = %d, want 0", b.Waiting())
+	}
+}
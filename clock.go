@@ -0,0 +1,24 @@
+package pipedream
+
+import "time"
+
+// Clock abstracts the passage of time so that timeouts, backoff and expiry can be controlled in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel that receives the current time once the duration has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is a Clock backed by the time package.
+type SystemClock struct {
+}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
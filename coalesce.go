@@ -0,0 +1,92 @@
+package pipedream
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup collapses concurrent calls with the same key into a single upstream call.
+type flightGroup struct {
+	keyFunc CacheKeyFunc
+
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	resp    any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newFlightGroup(keyFunc CacheKeyFunc) *flightGroup {
+	if keyFunc == nil {
+		keyFunc = HashParams
+	}
+	return &flightGroup{
+		keyFunc: keyFunc,
+		flights: map[string]*flight{},
+	}
+}
+
+// wrap shares one call between all concurrent callers with equal params.
+// The shared call is detached from any single caller's context, and each caller stops waiting
+// when its own context is done. The shared call is only cancelled once every caller has given up.
+// All callers receive the same response value, so it should not be mutated.
+func (g *flightGroup) wrap(next GetFunc) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		key, err := g.keyFunc(params)
+		if err != nil {
+			return next(ctx, params)
+		}
+
+		g.mu.Lock()
+		f, ok := g.flights[key]
+		if !ok {
+			fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+			f = &flight{done: make(chan struct{}), cancel: cancel}
+			g.flights[key] = f
+			go g.run(fctx, key, f, next, params)
+		}
+		f.waiters++
+		g.mu.Unlock()
+
+		select {
+		case <-f.done:
+			return f.resp, f.err
+		case <-ctx.Done():
+			g.leave(key, f)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, f *flight, next GetFunc, params any) {
+	defer f.cancel()
+
+	f.resp, f.err = next(ctx, params)
+
+	g.mu.Lock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	g.mu.Unlock()
+	close(f.done)
+}
+
+// leave removes a caller that gave up waiting, cancelling the shared call if it was the last one.
+func (g *flightGroup) leave(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f.waiters--
+	if f.waiters == 0 {
+		f.cancel()
+		// Later callers should start a fresh call rather than join a cancelled one.
+		if g.flights[key] == f {
+			delete(g.flights, key)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// heldGetter returns a GetFunc that counts its calls and blocks until release is closed or its context is done.
+// The context error of each call that was cancelled is sent on canceled.
+func heldGetter(calls *atomic.Int32, release <-chan struct{}, canceled chan<- error) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		calls.Add(1)
+		select {
+		case <-release:
+			return params.(int) * 2, nil
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// waiters returns the number of callers waiting on the flight for the key of params.
+func (g *flightGroup) waiters(t *testing.T, params any) int {
+	key, err := g.keyFunc(params)
+	if err != nil {
+		t.Fatalf("keyFunc returned error: %v", err)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if f, ok := g.flights[key]; ok {
+		return f.waiters
+	}
+	return 0
+}
+
+func TestCoalescingSharesOneCall(t *testing.T) {
+	const n = 10
+	var calls atomic.Int32
+	release := make(chan struct{})
+	g := newFlightGroup(nil)
+	get := g.wrap(heldGetter(&calls, release, make(chan error, 1)))
+
+	results := make(chan any, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := get(context.Background(), 2)
+			if err != nil {
+				t.Errorf("Get returned error: %v", err)
+			}
+			results <- v
+		}()
+	}
+	waitFor(t, func() bool { return g.waiters(t, 2) == n })
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if calls.Load() != 1 {
+		t.Errorf("getter called %d times, want 1", calls.Load())
+	}
+	for v := range results {
+		if v != 4 {
+			t.Errorf("Get = %v, want 4", v)
+		}
+	}
+
+	// Once the call finished, the next caller starts a new one.
+	release = make(chan struct{})
+	close(release)
+	get = g.wrap(heldGetter(&calls, release, nil))
+	get(context.Background(), 2)
+	if calls.Load() != 2 {
+		t.Errorf("getter called %d times, want a new call after the first finished", calls.Load())
+	}
+}
+
+func TestCoalescingCanceledCaller(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	canceled := make(chan error, 1)
+	g := newFlightGroup(nil)
+	get := g.wrap(heldGetter(&calls, release, canceled))
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := get(context.Background(), 2); err != nil || v != 4 {
+				t.Errorf("Get = %v, %v, want 4, nil", v, err)
+			}
+		}()
+	}
+	waitFor(t, func() bool { return g.waiters(t, 2) == 2 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := get(ctx, 2)
+		errs <- err
+	}()
+	waitFor(t, func() bool { return g.waiters(t, 2) == 3 })
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled caller got %v, want context.Canceled", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("getter called %d times, want 1", calls.Load())
+	}
+	select {
+	case err := <-canceled:
+		t.Errorf("shared call canceled with %v while callers were waiting", err)
+	default:
+	}
+}
+
+func TestCoalescingLastCallerCancels(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	canceled := make(chan error, 1)
+	g := newFlightGroup(nil)
+	get := g.wrap(heldGetter(&calls, release, canceled))
+
+	ctxs := make([]context.Context, 2)
+	cancels := make([]context.CancelFunc, 2)
+	errs := make(chan error, 2)
+	for i := range ctxs {
+		ctxs[i], cancels[i] = context.WithCancel(context.Background())
+		go func() {
+			_, err := get(ctxs[i], 2)
+			errs <- err
+		}()
+	}
+	waitFor(t, func() bool { return g.waiters(t, 2) == 2 })
+
+	cancels[0]()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller got %v, want context.Canceled", err)
+	}
+	select {
+	case err := <-canceled:
+		t.Fatalf("shared call canceled with %v while a caller was waiting", err)
+	default:
+	}
+
+	// The last caller giving up cancels the shared call.
+	cancels[1]()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("last caller got %v, want context.Canceled", err)
+	}
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Errorf("shared call got %v, want context.Canceled", err)
+	}
+
+	// Later callers start a fresh call rather than joining the canceled one.
+	close(release)
+	if v, err := get(context.Background(), 2); err != nil || v != 4 {
+		t.Errorf("Get = %v, %v, want 4, nil", v, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("getter called %d times, want 2", calls.Load())
+	}
+}
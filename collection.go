@@ -0,0 +1,117 @@
+package pipedream
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var ErrNotACollection = fmt.Errorf("value is not a collection")
+
+// RangeElements calls yield for each element of a slice, array, map, iter.Seq or iter.Seq2, stopping early
+// if yield returns false or an error. The key is the index for slices, arrays and sequences,
+// and the map key for maps and iter.Seq2 sequences.
+//
+// An iter.Seq2 whose second value is an error is treated as a stream: the first non-nil error ends
+// the iteration and is returned. Sequences are consumed lazily, one element at a time.
+func RangeElements(collection any, yield func(key any, elem any) (bool, error)) error {
+	v := reflect.ValueOf(collection)
+	if !v.IsValid() {
+		return ErrInputIsNil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return ErrInputIsNil
+		}
+		return RangeElements(v.Elem().Interface(), yield)
+	case reflect.Array, reflect.Slice:
+		for i := range v.Len() {
+			if cont, err := yield(i, v.Index(i).Interface()); err != nil || !cont {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if cont, err := yield(iter.Key().Interface(), iter.Value().Interface()); err != nil || !cont {
+				return err
+			}
+		}
+		return nil
+	case reflect.Func:
+		return rangeSeq(v, yield)
+	default:
+		return fmt.Errorf("%w: %s", ErrNotACollection, v.Type())
+	}
+}
+
+func rangeSeq(v reflect.Value, yield func(key any, elem any) (bool, error)) error {
+	t := v.Type()
+	if v.IsNil() {
+		return ErrInputIsNil
+	}
+
+	var err error
+	switch {
+	case isStreamType(t):
+		i := 0
+		for elem, elemErr := range v.Seq2() {
+			if !elemErr.IsNil() {
+				return elemErr.Interface().(error)
+			}
+			var cont bool
+			if cont, err = yield(i, elem.Interface()); err != nil || !cont {
+				break
+			}
+			i++
+		}
+	case t.CanSeq2():
+		for key, elem := range v.Seq2() {
+			var cont bool
+			if cont, err = yield(key.Interface(), elem.Interface()); err != nil || !cont {
+				break
+			}
+		}
+	case t.CanSeq():
+		i := 0
+		for elem := range v.Seq() {
+			var cont bool
+			if cont, err = yield(i, elem.Interface()); err != nil || !cont {
+				break
+			}
+			i++
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrNotACollection, t)
+	}
+	return err
+}
+
+// isStreamType reports whether t is an iter.Seq2 whose second value is an error.
+func isStreamType(t reflect.Type) bool {
+	return t.Kind() == reflect.Func && t.CanSeq2() && t.In(0).In(1) == reflect.TypeFor[error]()
+}
+
+// ElementType returns the static type of the elements RangeElements would yield for a collection of type t,
+// or nil if it is unknown.
+func ElementType(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return ElementType(t.Elem())
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return staticType(t.Elem())
+	case reflect.Func:
+		switch {
+		case t.CanSeq2() && !isStreamType(t):
+			return staticType(t.In(0).In(1))
+		case t.CanSeq2(), t.CanSeq():
+			return staticType(t.In(0).In(0))
+		}
+	}
+	return nil
+}
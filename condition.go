@@ -2,6 +2,7 @@ package pipedream
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"reflect"
 )
@@ -84,6 +85,22 @@ func (c *ValueCondition) Evaluate(pctx PipelineContext) (bool, error) {
 	return compareValues(lhsVal, rhsVal, c.Operand)
 }
 
+// Validate implements the Validator interface for ValueCondition.
+func (c *ValueCondition) Validate(vctx ValidationContext) {
+	if c.Operand == ConditionOperandInvalid {
+		vctx.Sub("Operand").Report(fmt.Errorf("%w: operand is %s", ErrInvalidCondition, c.Operand))
+	}
+	vctx.Sub("LHS").ValidateValue(c.LHS)
+	vctx.Sub("RHS").ValidateValue(c.RHS)
+
+	if c.Operand == ConditionOperandInvalid || c.LHS == nil || c.RHS == nil {
+		return
+	}
+	if err := checkCompareTypes(vctx.TypeOf(c.LHS), vctx.TypeOf(c.RHS), c.Operand); err != nil {
+		vctx.Report(err)
+	}
+}
+
 // AndCondition only evaluates to true if all the conditions inside also evaluate to true.
 // Short-circuits as soon as one condition is false.
 type AndCondition struct {
@@ -115,6 +132,13 @@ func (c *AndCondition) Evaluate(pctx PipelineContext) (bool, error) {
 	return true, nil
 }
 
+// Validate implements the Validator interface for AndCondition.
+func (c *AndCondition) Validate(vctx ValidationContext) {
+	for i, cond := range c.Conditions {
+		vctx.Sub(fmt.Sprintf("Conditions[%d]", i)).ValidateCondition(cond)
+	}
+}
+
 // OrCondition evaluates to true if any of the conditions inside evaluates to true.
 // Short-circuits as soon as one condition is true.
 type OrCondition struct {
@@ -146,6 +170,13 @@ func (c *OrCondition) Evaluate(pctx PipelineContext) (bool, error) {
 	return false, nil
 }
 
+// Validate implements the Validator interface for OrCondition.
+func (c *OrCondition) Validate(vctx ValidationContext) {
+	for i, cond := range c.Conditions {
+		vctx.Sub(fmt.Sprintf("Conditions[%d]", i)).ValidateCondition(cond)
+	}
+}
+
 // Generic comparison function for ordered types
 func compareOrdered[T cmp.Ordered](l, r T, op ConditionOperand) (bool, error) {
 	switch op {
@@ -271,6 +302,39 @@ func compareValues(lhs, rhs any, op ConditionOperand) (bool, error) {
 	return false, fmt.Errorf("%w: cannot compare types %s and %s with operand %s", ErrIncompatibleTypes, lhsV.Type(), rhsV.Type(), op.String())
 }
 
+// checkCompareTypes statically checks that values of the two types can be compared with the operand,
+// following the same rules as compareValues. Unknown (nil) types are assumed to be compatible.
+func checkCompareTypes(lhsT, rhsT reflect.Type, op ConditionOperand) error {
+	if lhsT == nil || rhsT == nil {
+		return nil
+	}
+	lhsK := lhsT.Kind()
+	rhsK := rhsT.Kind()
+	isEquality := op == ConditionEqual || op == ConditionNotEqual
+
+	// Pointers may be nil at runtime, which can be checked for equality against anything.
+	if isEquality && (lhsK == reflect.Pointer || rhsK == reflect.Pointer) {
+		return nil
+	}
+
+	if lhsT == rhsT {
+		switch {
+		case isNumeric(lhsK), lhsK == reflect.String:
+			return nil
+		case isEquality:
+			return nil
+		default:
+			return fmt.Errorf("%w: operand %s not supported for type %s", ErrOperationNotSupported, op.String(), lhsT)
+		}
+	}
+
+	if isNumeric(lhsK) && isNumeric(rhsK) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: cannot compare types %s and %s with operand %s", ErrIncompatibleTypes, lhsT, rhsT, op.String())
+}
+
 // --- Helper functions for comparison ---
 
 func isSignedInteger(k reflect.Kind) bool {
@@ -466,3 +530,68 @@ func (c *CustomCompareCondition[T]) Evaluate(pctx PipelineContext) (bool, error)
 	// Call the custom comparison function with the correctly typed values
 	return c.CompareFunc(lhsValTyped, rhsValTyped)
 }
+
+// Validate implements the Validator interface for CustomCompareCondition.
+func (c *CustomCompareCondition[T]) Validate(vctx ValidationContext) {
+	if c.CompareFunc == nil {
+		vctx.Sub("CompareFunc").Report(ErrNilCustomCompareFunc)
+	}
+	vctx.Sub("LHS").ValidateValue(c.LHS)
+	vctx.Sub("RHS").ValidateValue(c.RHS)
+
+	expectedType := reflect.TypeFor[T]()
+	if t := vctx.TypeOf(c.LHS); t != nil && !isAssertableTo(t, expectedType) {
+		vctx.Sub("LHS").Report(fmt.Errorf("%w: expected LHS type '%s', got '%s'", ErrTypeAssertionFailed, expectedType, t))
+	}
+	if t := vctx.TypeOf(c.RHS); t != nil && !isAssertableTo(t, expectedType) {
+		vctx.Sub("RHS").Report(fmt.Errorf("%w: expected RHS type '%s', got '%s'", ErrTypeAssertionFailed, expectedType, t))
+	}
+}
+
+// isAssertableTo reports whether a value of type t can be type asserted to the expected type.
+func isAssertableTo(t, expected reflect.Type) bool {
+	if expected.Kind() == reflect.Interface {
+		return t.Implements(expected)
+	}
+	return t == expected
+}
+
+// ErrorIsCondition is true if the error built by Error matches Target according to errors.Is.
+// It is false if Error builds a nil value, e.g. when checking the error a TryNode stored for a body that succeeded.
+type ErrorIsCondition struct {
+	Error  ValueBuilder
+	Target error
+}
+
+// Evaluate implements the Condition interface for ErrorIsCondition.
+func (c *ErrorIsCondition) Evaluate(pctx PipelineContext) (bool, error) {
+	if c.Error == nil || c.Target == nil {
+		return false, fmt.Errorf("%w: Error builder or Target is nil in ErrorIsCondition", ErrInvalidCondition)
+	}
+
+	v, err := c.Error.Build(pctx)
+	if err != nil {
+		return false, fmt.Errorf("evaluating error: %w", err)
+	}
+	if v == nil {
+		return false, nil
+	}
+	e, ok := v.(error)
+	if !ok {
+		return false, fmt.Errorf("%w: expected an error, got '%s'", ErrTypeAssertionFailed, reflect.TypeOf(v))
+	}
+	return errors.Is(e, c.Target), nil
+}
+
+// Validate implements the Validator interface for ErrorIsCondition.
+func (c *ErrorIsCondition) Validate(vctx ValidationContext) {
+	if c.Target == nil {
+		vctx.Sub("Target").Report(fmt.Errorf("%w: no target error", ErrInvalidCondition))
+	}
+	vctx.Sub("Error").ValidateValue(c.Error)
+
+	errorType := reflect.TypeFor[error]()
+	if t := vctx.TypeOf(c.Error); t != nil && !isAssertableTo(t, errorType) {
+		vctx.Sub("Error").Report(fmt.Errorf("%w: expected an error, got '%s'", ErrTypeAssertionFailed, t))
+	}
+}
@@ -2,26 +2,104 @@ package pipedream
 
 import "maps"
 
+// RedactedValue replaces the values of sensitive keys in snapshots of a PipelineContext.
+const RedactedValue = "[REDACTED]"
+
 type PipelineContext struct {
-	values map[string]any
+	values    map[string]any
+	sensitive map[string]struct{}
+
+	// accessed records the names read or written through this copy of the context, if it is not nil.
+	accessed map[string]struct{}
+}
+
+// NewPipelineContext returns an empty PipelineContext ready for use.
+func NewPipelineContext() PipelineContext {
+	return PipelineContext{
+		values:    map[string]any{},
+		sensitive: map[string]struct{}{},
+	}
 }
 
 // GetValue gets the value with the specified name, if it exists.
 func (p PipelineContext) GetValue(k string) (any, bool) {
+	p.access(k)
 	v, b := p.values[k]
 	return v, b
 }
 
 // SetValue sets the value provided to the specified name, returning any value that was already there.
 func (p PipelineContext) SetValue(k string, v any) (any, bool) {
+	p.access(k)
 	oldV, b := p.values[k]
 	p.values[k] = v
 
 	return oldV, b
 }
 
+// MarkSensitive marks the value with the specified name as sensitive, so it is redacted from logs and snapshots.
+func (p PipelineContext) MarkSensitive(k string) {
+	p.sensitive[k] = struct{}{}
+}
+
+// IsSensitive reports whether the value with the specified name was marked as sensitive.
+func (p PipelineContext) IsSensitive(k string) bool {
+	_, ok := p.sensitive[k]
+	return ok
+}
+
+// Snapshot returns a copy of all values, with the values of sensitive names replaced by RedactedValue.
+func (p PipelineContext) Snapshot() map[string]any {
+	snapshot := make(map[string]any, len(p.values))
+	for k, v := range p.values {
+		if p.IsSensitive(k) {
+			v = RedactedValue
+		}
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// trackAccess returns a copy of the context that records the names read or written through it.
+// Clones of the copy do not record anything.
+func (p PipelineContext) trackAccess() PipelineContext {
+	p.accessed = map[string]struct{}{}
+	return p
+}
+
+func (p PipelineContext) access(k string) {
+	if p.accessed != nil {
+		p.accessed[k] = struct{}{}
+	}
+}
+
+// accessedSnapshot is like Snapshot, but only includes the names read or written through a context
+// returned by trackAccess since its record was last cleared.
+func (p PipelineContext) accessedSnapshot() map[string]any {
+	snapshot := make(map[string]any, len(p.accessed))
+	for k := range p.accessed {
+		v, ok := p.values[k]
+		if !ok {
+			continue
+		}
+		if p.IsSensitive(k) {
+			v = RedactedValue
+		}
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// Merge sets every value from other, and marks the names that are sensitive in other as sensitive.
+// Use it to commit the writes made to a clone back to the original.
+func (p PipelineContext) Merge(other PipelineContext) {
+	maps.Copy(p.values, other.values)
+	maps.Copy(p.sensitive, other.sensitive)
+}
+
 func (p PipelineContext) Clone() PipelineContext {
 	return PipelineContext{
-		values: maps.Clone(p.values),
+		values:    maps.Clone(p.values),
+		sensitive: maps.Clone(p.sensitive),
 	}
 }
@@ -4,48 +4,178 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 )
 
 var ErrParamTypeDoesNotMatch = fmt.Errorf("cannot convert provided params to appropriate type for this data source")
+var ErrResponseTypeDoesNotMatch = fmt.Errorf("data source response is not of the requested type")
 
 type DataGetter[T any, U any] func(ctx context.Context, params T) (U, error)
 
+// GetFunc is a type-erased DataGetter, as called by DataSource.Get.
+type GetFunc func(ctx context.Context, params any) (any, error)
+
+// Middleware wraps the calls a DataSource makes to its getter, e.g. to add circuit breaking or caching.
+type Middleware interface {
+	Wrap(next GetFunc) GetFunc
+}
+
+// MiddlewareFunc adapts a function to the Middleware interface.
+type MiddlewareFunc func(next GetFunc) GetFunc
+
+func (f MiddlewareFunc) Wrap(next GetFunc) GetFunc {
+	return f(next)
+}
+
 type DataSource struct {
 	Name string
 
-	getter any
+	convert func(params any) (any, error)
+	call    GetFunc
+	collect func(resp any, limit int) (any, error) // Only set for stream data sources.
+
+	wrappers     []string // Names of the layers around the getter, outermost first.
+	healthChecks []HealthChecker
 
 	paramType    reflect.Type
 	responseType reflect.Type
 }
 
+// NewDataSource creates a DataSource from a typed getter.
+// Options such as WithTimeout and WithRetry are applied transparently on every call to Get.
 func NewDataSource[T any, U any](
 	name string,
 	getter DataGetter[T, U],
+	opts ...DataSourceOption,
 ) DataSource {
+	paramType := reflect.TypeFor[T]()
+	config := newDataSourceConfig(opts)
+	call := config.wrap(func(ctx context.Context, params any) (any, error) {
+		// Params were already converted by Get, so this only fails for a nil interface value.
+		p, _ := params.(T)
+		return getter(ctx, p)
+	})
+
 	return DataSource{
-		Name:         name,
-		getter:       getter,
-		paramType:    reflect.TypeFor[T](),
+		Name: name,
+		convert: func(params any) (any, error) {
+			return convertParams[T](params, paramType)
+		},
+		call:         call,
+		wrappers:     config.names(),
+		healthChecks: config.healthChecks,
+		paramType:    paramType,
 		responseType: reflect.TypeFor[U](),
 	}
 }
 
+// ParamType returns the type of params the data source accepts.
+func (d DataSource) ParamType() reflect.Type {
+	return d.paramType
+}
+
+// ResponseType returns the type of responses the data source returns.
+func (d DataSource) ResponseType() reflect.Type {
+	return d.responseType
+}
+
+// WithMiddleware returns a copy of the data source whose calls go through the given middleware.
+// The first middleware is outermost. Params are converted to the getter's param type before any middleware runs.
+// Middleware that implements HealthChecker is included in the data source's health checks.
+func (d DataSource) WithMiddleware(mws ...Middleware) DataSource {
+	names := make([]string, 0, len(mws)+len(d.wrappers))
+	for _, mw := range mws {
+		names = append(names, middlewareName(mw))
+	}
+	d.wrappers = append(names, d.wrappers...)
+
+	d.healthChecks = slices.Clone(d.healthChecks)
+	for i := len(mws) - 1; i >= 0; i-- {
+		d.call = mws[i].Wrap(d.call)
+		if checker, ok := mws[i].(HealthChecker); ok {
+			d.healthChecks = append(d.healthChecks, checker)
+		}
+	}
+	return d
+}
+
+// middlewareName describes middleware in a data source's wrapper stack,
+// using its String method if it has one and its type otherwise.
+func middlewareName(mw Middleware) string {
+	if s, ok := mw.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", mw)
+}
+
 func (d DataSource) Get(ctx context.Context, params any) (any, error) {
-	// Since this value is provided we need to check that it is of the correct type.
-	paramsValue := reflect.ValueOf(params)
-	if !paramsValue.CanConvert(d.paramType) {
-		return nil, ErrParamTypeDoesNotMatch
+	p, err := d.convert(params)
+	if err != nil {
+		return nil, &dataSourceError{name: d.Name, err: err}
+	}
+	resp, err := d.call(ctx, p)
+	if err != nil {
+		return nil, &dataSourceError{name: d.Name, err: err}
 	}
+	return resp, nil
+}
 
-	// Don't really need to check these since it was provided at construction in a type-safe way.
-	f := reflect.ValueOf(d.getter)
-	resps := f.Call([]reflect.Value{
-		reflect.ValueOf(ctx),
-		paramsValue.Convert(d.paramType),
-	})
+// Query gets the named data source from the execution context and queries it with typed params,
+// asserting the response to U. A nil response is only accepted if U can be nil.
+func Query[T any, U any](ectx ExecutionContext, name string, params T) (U, error) {
+	var zeroU U
 
-	resp, err := resps[0].Convert(d.responseType).Interface(), resps[1].Convert(reflect.TypeFor[error]()).Interface().(error)
+	dataSource, err := ectx.GetDataSource(name)
+	if err != nil {
+		return zeroU, fmt.Errorf("%w: %s", err, name)
+	}
 
-	return resp, err
+	resp, err := dataSource.Get(ectx.Context(), params)
+	if err != nil {
+		return zeroU, err
+	}
+	if resp == nil {
+		// Untyped nil is only acceptable for types that can be nil.
+		if !nilable(reflect.TypeFor[U]()) {
+			return zeroU, fmt.Errorf("%w: expected '%s', got nil", ErrResponseTypeDoesNotMatch, reflect.TypeFor[U]())
+		}
+		return zeroU, nil
+	}
+	typedResp, ok := resp.(U)
+	if !ok {
+		return zeroU, fmt.Errorf("%w: expected '%s', got '%s'", ErrResponseTypeDoesNotMatch, reflect.TypeFor[U](), reflect.TypeOf(resp))
+	}
+	return typedResp, nil
+}
+
+// convertParams asserts the params to T, falling back to a reflect conversion for params of a convertible type.
+func convertParams[T any](params any, paramType reflect.Type) (T, error) {
+	if p, ok := params.(T); ok {
+		return p, nil
+	}
+
+	var zeroT T
+	v := reflect.ValueOf(params)
+	if !v.IsValid() {
+		// Untyped nil is only acceptable for types that can be nil.
+		if !nilable(paramType) {
+			return zeroT, ErrParamTypeDoesNotMatch
+		}
+		return zeroT, nil
+	}
+	if !v.CanConvert(paramType) {
+		return zeroT, ErrParamTypeDoesNotMatch
+	}
+
+	return v.Convert(paramType).Interface().(T), nil
+}
+
+// nilable reports whether values of the type can be nil.
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,209 @@
+package pipedream
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrDataSourceTimeout = fmt.Errorf("data source call timed out")
+var ErrRetriesExhausted = fmt.Errorf("data source retries exhausted")
+
+// DataSourceOption configures how a DataSource calls its getter.
+type DataSourceOption func(*dataSourceConfig)
+
+// BackoffFunc returns how long to wait before the given retry. The first retry is attempt 1.
+type BackoffFunc func(retry int) time.Duration
+
+type dataSourceConfig struct {
+	timeout time.Duration
+
+	maxAttempts int
+	backoff     BackoffFunc
+	retryable   func(err error) bool
+	jitter      float64
+	random      func() float64
+
+	coalesce        bool
+	coalesceKeyFunc CacheKeyFunc
+
+	healthChecks []HealthChecker
+
+	clock Clock
+}
+
+// WithTimeout limits how long a single call to the getter may take.
+// The getter's context is cancelled and ErrDataSourceTimeout is returned once the timeout elapses.
+// With retries, the timeout applies to each attempt.
+func WithTimeout(timeout time.Duration) DataSourceOption {
+	return func(c *dataSourceConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetry retries failed calls up to maxAttempts calls in total, waiting according to backoff between them.
+// If retryable is nil, all errors are retried. Calls are never retried once the caller's context is done.
+// A nil backoff retries immediately.
+func WithRetry(maxAttempts int, backoff BackoffFunc, retryable func(err error) bool) DataSourceOption {
+	return func(c *dataSourceConfig) {
+		c.maxAttempts = maxAttempts
+		c.backoff = backoff
+		c.retryable = retryable
+	}
+}
+
+// WithJitter randomly shortens each backoff wait by up to the given fraction (between 0 and 1),
+// so that callers retrying at the same time spread out.
+func WithJitter(fraction float64) DataSourceOption {
+	return func(c *dataSourceConfig) {
+		c.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// WithRandom sets the source of random numbers in [0, 1) used for jitter, e.g. to make it predictable in tests.
+// It may be called concurrently. The default is math/rand/v2's Float64.
+func WithRandom(random func() float64) DataSourceOption {
+	return func(c *dataSourceConfig) {
+		c.random = random
+	}
+}
+
+// WithCoalescing collapses concurrent calls with equal params into a single call to the getter,
+// sharing its response between all callers. Params are compared by the keys keyFunc derives from them;
+// if keyFunc is nil, HashParams is used. Each caller still stops waiting as soon as its own context is done.
+func WithCoalescing(keyFunc CacheKeyFunc) DataSourceOption {
+	return func(c *dataSourceConfig) {
+		c.coalesce = true
+		c.coalesceKeyFunc = keyFunc
+	}
+}
+
+// WithClock sets the Clock used for timeouts and backoff. The default is SystemClock.
+func WithClock(clock Clock) DataSourceOption {
+	return func(c *dataSourceConfig) {
+		c.clock = clock
+	}
+}
+
+// ConstantBackoff waits the same duration before every retry.
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(retry int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the wait before each retry, starting at initial and never exceeding maxWait.
+func ExponentialBackoff(initial time.Duration, maxWait time.Duration) BackoffFunc {
+	return func(retry int) time.Duration {
+		d := initial
+		for i := 1; i < retry && d < maxWait; i++ {
+			d *= 2
+		}
+		return min(d, maxWait)
+	}
+}
+
+func newDataSourceConfig(opts []DataSourceOption) dataSourceConfig {
+	c := dataSourceConfig{
+		maxAttempts: 1,
+		random:      rand.Float64,
+		clock:       SystemClock{},
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// wrap applies the configured behaviour around the getter. Timeouts apply to each retry attempt,
+// and coalesced callers share the whole sequence of retries.
+func (c dataSourceConfig) wrap(get GetFunc) GetFunc {
+	if c.timeout > 0 {
+		get = c.withTimeout(get)
+	}
+	if c.maxAttempts > 1 {
+		get = c.withRetry(get)
+	}
+	if c.coalesce {
+		get = newFlightGroup(c.coalesceKeyFunc).wrap(get)
+	}
+	return get
+}
+
+// names describes the layers wrap adds, outermost first.
+func (c dataSourceConfig) names() []string {
+	var names []string
+	if c.coalesce {
+		names = append(names, "Coalescing")
+	}
+	if c.maxAttempts > 1 {
+		names = append(names, fmt.Sprintf("Retry(%d)", c.maxAttempts))
+	}
+	if c.timeout > 0 {
+		names = append(names, fmt.Sprintf("Timeout(%s)", c.timeout))
+	}
+	return names
+}
+
+func (c dataSourceConfig) withTimeout(next GetFunc) GetFunc {
+	type result struct {
+		resp any
+		err  error
+	}
+
+	return func(ctx context.Context, params any) (any, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		done := make(chan result, 1)
+		go func() {
+			resp, err := next(ctx, params)
+			done <- result{resp: resp, err: err}
+		}()
+
+		select {
+		case r := <-done:
+			return r.resp, r.err
+		case <-c.clock.After(c.timeout):
+			return nil, fmt.Errorf("%w after %s", ErrDataSourceTimeout, c.timeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c dataSourceConfig) withRetry(next GetFunc) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		for attempt := 1; ; attempt++ {
+			resp, err := next(ctx, params)
+			if err == nil {
+				return resp, nil
+			}
+			if ctx.Err() != nil || (c.retryable != nil && !c.retryable(err)) {
+				return resp, err
+			}
+			if attempt >= c.maxAttempts {
+				return resp, fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempt, err)
+			}
+
+			select {
+			case <-c.clock.After(c.wait(attempt)):
+			case <-ctx.Done():
+				return nil, fmt.Errorf("retry interrupted after %d attempts: %w", attempt, ctx.Err())
+			}
+		}
+	}
+}
+
+// wait returns how long to wait before the given retry, with jitter applied.
+func (c dataSourceConfig) wait(retry int) time.Duration {
+	if c.backoff == nil {
+		return 0
+	}
+	d := c.backoff(retry)
+	if c.jitter > 0 {
+		d -= time.Duration(float64(d) * c.jitter * c.random())
+	}
+	return d
+}
@@ -0,0 +1,137 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock records the waits it is asked for. Its timers fire at once, unless timers is set,
+// in which case each timer's channel is sent there for the test to fire. Time only passes with Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	waits  []time.Duration
+	timers chan chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if c.timers != nil {
+		c.timers <- ch
+	} else {
+		ch <- time.Time{}
+	}
+	return ch
+}
+
+// failTimes returns a getter that fails the given number of times before succeeding, counting its calls.
+func failTimes(n int, calls *int) DataGetter[int, int] {
+	return func(ctx context.Context, params int) (int, error) {
+		*calls++
+		if *calls <= n {
+			return 0, errNode
+		}
+		return params, nil
+	}
+}
+
+func TestRetryWithJitter(t *testing.T) {
+	clock := &fakeClock{}
+	var calls int
+	source := NewDataSource("flaky", failTimes(2, &calls),
+		WithRetry(3, ConstantBackoff(100*time.Millisecond), nil),
+		WithJitter(0.5),
+		WithRandom(func() float64 { return 0.5 }),
+		WithClock(clock),
+	)
+
+	resp, err := source.Get(context.Background(), 7)
+	if err != nil || resp != 7 {
+		t.Fatalf("Get = %v, %v, want 7, nil", resp, err)
+	}
+	if calls != 3 {
+		t.Errorf("getter called %d times, want 3", calls)
+	}
+	if want := []time.Duration{75 * time.Millisecond, 75 * time.Millisecond}; !slices.Equal(clock.waits, want) {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	clock := &fakeClock{}
+	var calls int
+	source := NewDataSource("failing", failTimes(5, &calls),
+		WithRetry(3, ExponentialBackoff(10*time.Millisecond, time.Second), nil),
+		WithClock(clock),
+	)
+
+	_, err := source.Get(context.Background(), 1)
+	if !errors.Is(err, ErrRetriesExhausted) || !errors.Is(err, errNode) || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("got %v, want ErrRetriesExhausted after 3 attempts wrapping errNode", err)
+	}
+	if want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}; !slices.Equal(clock.waits, want) {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	var calls int
+	source := NewDataSource("failing", failTimes(5, &calls),
+		WithRetry(3, nil, func(err error) bool { return false }),
+		WithClock(&fakeClock{}),
+	)
+
+	if _, err := source.Get(context.Background(), 1); !errors.Is(err, errNode) || errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("got %v, want errNode unwrapped", err)
+	}
+	if calls != 1 {
+		t.Errorf("getter called %d times, want 1", calls)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	clock := &fakeClock{timers: make(chan chan time.Time)}
+	getterErr := make(chan error, 1)
+	source := NewDataSource("slow", func(ctx context.Context, params int) (int, error) {
+		<-ctx.Done()
+		getterErr <- ctx.Err()
+		return 0, ctx.Err()
+	}, WithTimeout(time.Second), WithClock(clock))
+
+	go func() {
+		timer := <-clock.timers
+		timer <- time.Time{}
+	}()
+
+	_, err := source.Get(context.Background(), 1)
+	if !errors.Is(err, ErrDataSourceTimeout) {
+		t.Errorf("got %v, want ErrDataSourceTimeout", err)
+	}
+	if err := <-getterErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("getter's context: got %v, want it canceled", err)
+	}
+	if want := []time.Duration{time.Second}; !slices.Equal(clock.waits, want) {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+}
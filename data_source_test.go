@@ -0,0 +1,121 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func double(ctx context.Context, params int) (int, error) {
+	return params * 2, nil
+}
+
+func TestDataSourceGetNilError(t *testing.T) {
+	source := NewDataSource("double", double)
+
+	resp, err := source.Get(context.Background(), 21)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if resp != 42 {
+		t.Errorf("Get = %v, want 42", resp)
+	}
+}
+
+func TestDataSourceGetConvertsParams(t *testing.T) {
+	type id int
+	source := NewDataSource("double", double)
+
+	resp, err := source.Get(context.Background(), id(2))
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if resp != 4 {
+		t.Errorf("Get = %v, want 4", resp)
+	}
+
+	if _, err := source.Get(context.Background(), "2"); !errors.Is(err, ErrParamTypeDoesNotMatch) {
+		t.Errorf("Get with string params: got %v, want ErrParamTypeDoesNotMatch", err)
+	}
+}
+
+func TestDataSourceGetError(t *testing.T) {
+	boom := errors.New("boom")
+	source := NewDataSource("failing", func(ctx context.Context, params int) (int, error) {
+		return 0, boom
+	})
+
+	if _, err := source.Get(context.Background(), 1); !errors.Is(err, boom) {
+		t.Errorf("Get: got %v, want %v", err, boom)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ex := NewPipelineExecutor()
+	ex.RegisterDataSource(NewDataSource("double", double))
+	ex.RegisterDataSource(NewDataSource("nil", func(ctx context.Context, params int) (*int, error) {
+		return nil, nil
+	}))
+	ex.RegisterDataSource(NewDataSource("untyped", func(ctx context.Context, params int) (any, error) {
+		return nil, nil
+	}))
+
+	v, err := Query[int, int](ex.ectx, "double", 4)
+	if err != nil || v != 8 {
+		t.Errorf("Query(double) = %v, %v, want 8, nil", v, err)
+	}
+
+	if _, err := Query[int, string](ex.ectx, "double", 4); !errors.Is(err, ErrResponseTypeDoesNotMatch) {
+		t.Errorf("Query with wrong response type: got %v, want ErrResponseTypeDoesNotMatch", err)
+	}
+
+	p, err := Query[int, *int](ex.ectx, "nil", 1)
+	if err != nil || p != nil {
+		t.Errorf("Query(nil) = %v, %v, want nil, nil", p, err)
+	}
+
+	if s, err := Query[int, string](ex.ectx, "untyped", 1); !errors.Is(err, ErrResponseTypeDoesNotMatch) {
+		t.Errorf("Query(untyped nil) into a string = %q, %v, want ErrResponseTypeDoesNotMatch", s, err)
+	}
+	if m, err := Query[int, map[string]int](ex.ectx, "untyped", 1); err != nil || m != nil {
+		t.Errorf("Query(untyped nil) into a map = %v, %v, want nil, nil", m, err)
+	}
+
+	if _, err := Query[int, int](ex.ectx, "missing", 1); !errors.Is(err, ErrDataSourceNotFound) {
+		t.Errorf("Query(missing): got %v, want ErrDataSourceNotFound", err)
+	}
+}
+
+// reflectGet calls a getter the way data sources did before getters were wrapped in typed closures.
+func reflectGet(getter reflect.Value, ctx context.Context, params any) (any, error) {
+	out := getter.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(params)})
+	if err := out[1].Interface(); err != nil {
+		return nil, err.(error)
+	}
+	return out[0].Interface(), nil
+}
+
+func BenchmarkDataSourceGet(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("ReflectCall", func(b *testing.B) {
+		getter := reflect.ValueOf(DataGetter[int, int](double))
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := reflectGet(getter, ctx, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Closure", func(b *testing.B) {
+		source := NewDataSource("double", double)
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := source.Get(ctx, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
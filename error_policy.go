@@ -0,0 +1,194 @@
+package pipedream
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrNoFallbackTarget = fmt.Errorf("node does not save a result to fall back to")
+
+// DefaultDeadLetterName is the context key dead letters are appended to if the policy does not name one.
+const DefaultDeadLetterName = "dead_letters"
+
+// ErrorAction is what a pipeline does when a node fails.
+type ErrorAction int
+
+const (
+	FailFast          ErrorAction = iota // Stop the pipeline and return the error. This is the default.
+	ContinueOnError                      // Continue with the next node, returning all errors joined when the pipeline ends.
+	FallbackOnError                      // Write the policy's Fallback value to the node's result key and continue.
+	DeadLetterOnError                    // Append a DeadLetter to a list in the context and continue.
+)
+
+func (a ErrorAction) String() string {
+	switch a {
+	case FailFast:
+		return "FailFast"
+	case ContinueOnError:
+		return "ContinueOnError"
+	case FallbackOnError:
+		return "FallbackOnError"
+	case DeadLetterOnError:
+		return "DeadLetterOnError"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorPolicy decides what happens when a node fails. The zero value is FailFast.
+// Stopping with ErrPipelineExecutionStop is not a failure, and cancellation of the execution always fails fast.
+// Errors from a node's own work being canceled, such as a data source timing out, are handled by the policy.
+type ErrorPolicy struct {
+	Action ErrorAction
+
+	// Fallback builds the value written to the node's result key when Action is FallbackOnError.
+	// A node's own policy requires it to implement ResultSaver. A pipeline's policy does not apply
+	// to nodes that do not, so they fail fast.
+	Fallback ValueBuilder
+
+	// DeadLetterName is the context key holding the []DeadLetter when Action is DeadLetterOnError.
+	// Default DefaultDeadLetterName.
+	DeadLetterName string
+}
+
+// ResultSaver is implemented by nodes that save their result to a context key, so FallbackOnError can write there.
+type ResultSaver interface {
+	ResultKey() string
+}
+
+// DeadLetter records a node failure that was routed to the dead-letter list by DeadLetterOnError.
+type DeadLetter struct {
+	Path     string
+	NodeType string
+	Err      error
+}
+
+// ErrorPolicyNode runs a node with its own error policy, overriding the policy of the pipeline it is in.
+type ErrorPolicyNode struct {
+	Node   Node
+	Policy ErrorPolicy
+}
+
+// Execute implements the Node interface for ErrorPolicyNode.
+// The policy itself is applied by the enclosing pipeline.
+func (n ErrorPolicyNode) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	if n.Node == nil {
+		return ErrNilNode
+	}
+	return n.Node.Execute(ectx, pctx)
+}
+
+// Validate implements the Validator interface for ErrorPolicyNode.
+// The policy itself is validated by the enclosing pipeline.
+func (n ErrorPolicyNode) Validate(vctx ValidationContext) {
+	if n.Node == nil {
+		vctx.Report(ErrNilNode)
+		return
+	}
+	if validator, ok := n.Node.(Validator); ok {
+		validator.Validate(vctx)
+	}
+}
+
+// errorPolicyFor returns the policy for a node: its own if it is wrapped in an ErrorPolicyNode,
+// and the pipeline's otherwise.
+func (p Pipeline) errorPolicyFor(node Node) ErrorPolicy {
+	for {
+		switch n := node.(type) {
+		case ErrorPolicyNode:
+			return n.Policy
+		case LabeledNode:
+			node = n.Node
+		default:
+			return p.ErrorPolicy
+		}
+	}
+}
+
+// handle applies the policy to a node failure. It returns the error if the pipeline should stop,
+// and otherwise whether the error should be collected. info is the PipelineError carried by err,
+// and own is whether the policy is the node's own rather than the pipeline's.
+func (ep ErrorPolicy) handle(node Node, own bool, pctx PipelineContext, err error, info *PipelineError) (collect bool, stop error) {
+	switch ep.Action {
+	case ContinueOnError:
+		return true, nil
+	case FallbackOnError:
+		saver, ok := innerNode[ResultSaver](node)
+		if !ok && own {
+			return false, errors.Join(err, ErrNoFallbackTarget)
+		}
+		if !ok {
+			return false, err
+		}
+		if ep.Fallback == nil {
+			return false, errors.Join(err, fmt.Errorf("%w: no fallback value", ErrNilValueBuilder))
+		}
+		v, buildErr := ep.Fallback.Build(pctx)
+		if buildErr != nil {
+			return false, errors.Join(err, fmt.Errorf("failed to build fallback value: %w", buildErr))
+		}
+		pctx.SetValue(saver.ResultKey(), v)
+		return false, nil
+	case DeadLetterOnError:
+		name := ep.DeadLetterName
+		if name == "" {
+			name = DefaultDeadLetterName
+		}
+		letters, _ := pctx.GetValue(name)
+		deadLetters, _ := letters.([]DeadLetter)
+		pctx.SetValue(name, append(deadLetters, DeadLetter{Path: info.Path, NodeType: info.NodeType, Err: err}))
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// isCanceled reports whether the execution was canceled, in which case failures are never handled by a policy.
+func isCanceled(ectx ExecutionContext) bool {
+	return ectx.Context().Err() != nil
+}
+
+// validate checks the policy can be applied to the node, and declares the keys it writes.
+// A pipeline's fallback policy is allowed to cover nodes that save nothing, which fail fast, but a node's own policy is not.
+func (ep ErrorPolicy) validate(vctx ValidationContext, node Node, own bool) {
+	switch ep.Action {
+	case FallbackOnError:
+		pv := vctx.Sub("ErrorPolicy")
+		saver, ok := innerNode[ResultSaver](node)
+		if !ok && own {
+			pv.Report(ErrNoFallbackTarget)
+		}
+		pv.Sub("Fallback").ValidateValue(ep.Fallback)
+		if ok && ep.Fallback != nil && vctx.KeyType(saver.ResultKey()) != vctx.TypeOf(ep.Fallback) {
+			// Either the node or the fallback may have written the key, so its type is unknown.
+			vctx.DeclareKey(saver.ResultKey())
+		}
+	case DeadLetterOnError:
+		name := ep.DeadLetterName
+		if name == "" {
+			name = DefaultDeadLetterName
+		}
+		vctx.DeclareKeyType(name, typeOfDeadLetters)
+	}
+}
+
+var typeOfDeadLetters = reflect.TypeFor[[]DeadLetter]()
+
+// innerNode finds T in the node, looking through LabeledNode and ErrorPolicyNode wrappers.
+func innerNode[T any](node Node) (T, bool) {
+	for {
+		if t, ok := node.(T); ok {
+			return t, true
+		}
+		switch n := node.(type) {
+		case LabeledNode:
+			node = n.Node
+		case ErrorPolicyNode:
+			node = n.Node
+		default:
+			var zero T
+			return zero, false
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errNode = errors.New("node failed")
+
+// failNode fails with its error, which defaults to errNode, and saves nothing.
+type failNode struct {
+	key string
+	err error
+}
+
+func (f failNode) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	if f.err != nil {
+		return f.err
+	}
+	return errNode
+}
+
+func (f failNode) ResultKey() string {
+	return f.key
+}
+
+// setNode sets a key to true.
+type setNode struct {
+	key string
+}
+
+func (s setNode) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	pctx.SetValue(s.key, true)
+	return nil
+}
+
+// funcNode adapts a function to the Node interface.
+type funcNode func(ectx ExecutionContext, pctx PipelineContext) error
+
+func (f funcNode) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	return f(ectx, pctx)
+}
+
+func runPipeline(t *testing.T, ctx context.Context, p Pipeline) (*Report, error) {
+	t.Helper()
+	_, report, err := NewPipelineExecutor().ExecuteWithReport(ctx, p)
+	return report, err
+}
+
+func TestErrorPolicyFailFast(t *testing.T) {
+	report, err := runPipeline(t, context.Background(), Pipeline{Nodes: []Node{failNode{}, setNode{"after"}}})
+
+	var pe *PipelineError
+	if !errors.As(err, &pe) || pe.Path != "nodes[0]" || !errors.Is(err, errNode) {
+		t.Fatalf("got %v, want PipelineError at nodes[0] wrapping errNode", err)
+	}
+	if _, ok := report.Context["after"]; ok {
+		t.Error("node after the failure ran")
+	}
+}
+
+func TestErrorPolicyContinue(t *testing.T) {
+	p := Pipeline{
+		ErrorPolicy: ErrorPolicy{Action: ContinueOnError},
+		Nodes:       []Node{failNode{}, setNode{"after"}, failNode{}},
+	}
+	report, err := runPipeline(t, context.Background(), p)
+
+	if !errors.Is(err, errNode) {
+		t.Fatalf("got %v, want errNode", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("got %v, want both errors joined", err)
+	}
+	if report.Context["after"] != true {
+		t.Error("node after the failure did not run")
+	}
+}
+
+func TestErrorPolicyFallback(t *testing.T) {
+	p := Pipeline{Nodes: []Node{
+		ErrorPolicyNode{
+			Node:   failNode{key: "result"},
+			Policy: ErrorPolicy{Action: FallbackOnError, Fallback: LiteralValue[int]{Value: 7}},
+		},
+	}}
+	report, err := runPipeline(t, context.Background(), p)
+
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	if report.Context["result"] != 7 {
+		t.Errorf("result = %v, want fallback 7", report.Context["result"])
+	}
+}
+
+func TestErrorPolicyDeadLetter(t *testing.T) {
+	p := Pipeline{
+		ErrorPolicy: ErrorPolicy{Action: DeadLetterOnError},
+		Nodes:       []Node{failNode{}, setNode{"after"}},
+	}
+	executor := NewPipelineExecutor()
+	pctx := NewPipelineContext()
+	_, err := executor.execute(context.Background(), p, pctx, nil, nil)
+
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	v, _ := pctx.GetValue(DefaultDeadLetterName)
+	letters, _ := v.([]DeadLetter)
+	if len(letters) != 1 || letters[0].Path != "nodes[0]" || !errors.Is(letters[0].Err, errNode) {
+		t.Errorf("dead letters = %v, want one for nodes[0]", letters)
+	}
+}
+
+func TestErrorPolicyCancellationFailsFast(t *testing.T) {
+	for _, action := range []ErrorAction{ContinueOnError, FallbackOnError, DeadLetterOnError} {
+		t.Run(action.String(), func(t *testing.T) {
+			policy := ErrorPolicy{Action: action, Fallback: LiteralValue[int]{Value: 7}}
+
+			// The node's error does not say it was canceled, but the execution was.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancelThenFail := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+				cancel()
+				return errNode
+			})
+			p := Pipeline{ErrorPolicy: policy, Nodes: []Node{ErrorPolicyNode{Node: cancelThenFail, Policy: policy}, setNode{"after"}}}
+			if _, err := NewPipelineExecutor().Execute(ctx, p); !errors.Is(err, errNode) {
+				t.Errorf("canceled execution: got %v, want the node's error", err)
+			}
+		})
+	}
+}
+
+func TestErrorPolicyHandlesTimeouts(t *testing.T) {
+	executor := NewPipelineExecutor()
+	executor.RegisterDataSource(NewDataSource("slow", func(ctx context.Context, params int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, WithTimeout(time.Second), WithClock(&fakeClock{})))
+
+	query := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		source, err := ectx.GetDataSource("slow")
+		if err != nil {
+			return err
+		}
+		_, err = source.Get(ectx.Context(), 1)
+		return err
+	})
+	// A node whose own deadline passes, while the execution's has not.
+	deadline := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		return context.DeadlineExceeded
+	})
+
+	p := Pipeline{
+		ErrorPolicy: ErrorPolicy{Action: ContinueOnError},
+		Nodes:       []Node{query, deadline, setNode{"after"}},
+	}
+	pctx := NewPipelineContext()
+	_, err := executor.execute(context.Background(), p, pctx, nil, nil)
+
+	if !errors.Is(err, ErrDataSourceTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want both the timeout and the deadline collected", err)
+	}
+	if v, _ := pctx.GetValue("after"); v != true {
+		t.Error("node after the timeouts did not run")
+	}
+}
+
+func TestErrorPolicyFailFastReturnsErrorUnjoined(t *testing.T) {
+	_, err := runPipeline(t, context.Background(), Pipeline{Nodes: []Node{failNode{}}})
+	if _, ok := err.(*PipelineError); !ok {
+		t.Errorf("got %T, want *PipelineError", err)
+	}
+}
+
+func TestErrorPolicyFallbackWithoutResult(t *testing.T) {
+	fallback := ErrorPolicy{Action: FallbackOnError, Fallback: LiteralValue[int]{Value: 7}}
+	executor := NewPipelineExecutor()
+
+	// A pipeline's fallback does not apply to nodes that save nothing, which fail fast.
+	p := Pipeline{ErrorPolicy: fallback, Nodes: []Node{funcNode(failNode{}.Execute), setNode{"after"}}}
+	if diags := executor.Validate(p); len(diags) != 0 {
+		t.Errorf("Validate = %v, want no diagnostics", diags)
+	}
+	report, err := runPipeline(t, context.Background(), p)
+	if !errors.Is(err, errNode) || errors.Is(err, ErrNoFallbackTarget) {
+		t.Errorf("got %v, want only the node's error", err)
+	}
+	if _, ok := report.Context["after"]; ok {
+		t.Error("node after the failure ran")
+	}
+
+	// A node's own fallback policy must have somewhere to write.
+	p = Pipeline{Nodes: []Node{ErrorPolicyNode{Node: funcNode(failNode{}.Execute), Policy: fallback}}}
+	if diags := executor.Validate(p); len(diags) != 1 || !errors.Is(diags[0], ErrNoFallbackTarget) {
+		t.Errorf("Validate = %v, want ErrNoFallbackTarget", diags)
+	}
+	if _, err := runPipeline(t, context.Background(), p); !errors.Is(err, ErrNoFallbackTarget) {
+		t.Errorf("got %v, want ErrNoFallbackTarget", err)
+	}
+}
@@ -0,0 +1,156 @@
+package pipedream
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PipelineError describes a node that failed. Errors returned by nodes are wrapped in a PipelineError
+// by the innermost pipeline running them, so callers can find out where execution failed with errors.As.
+type PipelineError struct {
+	// Path to the failing node, e.g. "nodes[3].TruePipeline.nodes[1]".
+	Path string
+
+	// NodeType is the type of the failing node, e.g. "nodes.QueryNode".
+	NodeType string
+
+	// NodeLabel is the label of the failing node, if it was given one with LabeledNode.
+	NodeLabel string
+
+	// DataSource is the name of the data source whose call failed, if the error came from one.
+	DataSource string
+
+	// Condition is the type of the condition whose evaluation failed, if the error came from one,
+	// and ConditionPath is its path, e.g. "nodes[3].Condition".
+	Condition     string
+	ConditionPath string
+
+	// Context is a snapshot of the values in the pipeline context that the node read or wrote, when it failed.
+	// Keys used only by nested pipelines or in clones of the context are not included.
+	// Values marked sensitive are replaced by RedactedValue.
+	Context map[string]any
+
+	Err error
+}
+
+func (e *PipelineError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Path)
+	b.WriteString(" (")
+	b.WriteString(e.NodeType)
+	if e.NodeLabel != "" {
+		fmt.Fprintf(&b, " %q", e.NodeLabel)
+	}
+	b.WriteString(")")
+	if e.DataSource != "" {
+		fmt.Fprintf(&b, ": data source %q", e.DataSource)
+	} else if e.Condition != "" {
+		fmt.Fprintf(&b, ": condition %s at %s", e.Condition, e.ConditionPath)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// LabeledNode gives a node a label, which is included in PipelineErrors it causes.
+// It behaves exactly like the node otherwise.
+type LabeledNode struct {
+	Label string
+	Node  Node
+}
+
+// Execute implements the Node interface for LabeledNode.
+func (l LabeledNode) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	if l.Node == nil {
+		return ErrNilNode
+	}
+	return l.Node.Execute(ectx, pctx)
+}
+
+// Validate implements the Validator interface for LabeledNode.
+func (l LabeledNode) Validate(vctx ValidationContext) {
+	if l.Node == nil {
+		vctx.Report(ErrNilNode)
+		return
+	}
+	if validator, ok := l.Node.(Validator); ok {
+		validator.Validate(vctx)
+	}
+}
+
+// newPipelineError wraps an error returned by a node, unless it already carries a PipelineError
+// from a nested pipeline.
+func newPipelineError(ectx ExecutionContext, node Node, pctx PipelineContext, err error) error {
+	var existing *PipelineError
+	if errors.As(err, &existing) {
+		return err
+	}
+
+	pe := &PipelineError{
+		Path:     ectx.path,
+		NodeType: nodeTypeName(node),
+		Context:  ectx.redact(pctx.accessedSnapshot()),
+		Err:      err,
+	}
+	if l, ok := innerNode[LabeledNode](node); ok {
+		pe.NodeLabel = l.Label
+	}
+	var dse *dataSourceError
+	if errors.As(err, &dse) {
+		pe.DataSource = dse.name
+	}
+	var ce *conditionError
+	if errors.As(err, &ce) {
+		pe.Condition = ce.condition
+		pe.ConditionPath = ce.path
+	}
+	return pe
+}
+
+// nodeTypeName returns the type name of the node, looking through LabeledNode and ErrorPolicyNode wrappers.
+func nodeTypeName(node Node) string {
+	for {
+		switch n := node.(type) {
+		case LabeledNode:
+			node = n.Node
+		case ErrorPolicyNode:
+			node = n.Node
+		default:
+			return typeName(node)
+		}
+	}
+}
+
+// dataSourceError annotates an error returned by a data source with its name, without changing its message.
+type dataSourceError struct {
+	name string
+	err  error
+}
+
+func (e *dataSourceError) Error() string {
+	return e.err.Error()
+}
+
+func (e *dataSourceError) Unwrap() error {
+	return e.err
+}
+
+// conditionError annotates an error from evaluating a condition with its type and path, without changing its message.
+type conditionError struct {
+	condition string
+	path      string
+	err       error
+}
+
+func (e *conditionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *conditionError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,38 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestPipelineErrorContextOnlyHasUsedKeys(t *testing.T) {
+	readThenFail := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		pctx.GetValue("input")
+		pctx.GetValue("token")
+		pctx.GetValue("missing")
+		pctx.SetValue("partial", 1)
+		return errNode
+	})
+	p := Pipeline{Nodes: []Node{
+		setNode{"input"},
+		setNode{"unrelated"},
+		funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+			pctx.SetValue("token", "secret")
+			pctx.MarkSensitive("token")
+			return nil
+		}),
+		readThenFail,
+	}}
+
+	_, err := NewPipelineExecutor().Execute(context.Background(), p)
+	var pe *PipelineError
+	if !errors.As(err, &pe) || pe.Path != "nodes[3]" {
+		t.Fatalf("got %v, want PipelineError at nodes[3]", err)
+	}
+	want := map[string]any{"input": true, "token": RedactedValue, "partial": 1}
+	if !maps.Equal(pe.Context, want) {
+		t.Errorf("Context = %v, want %v", pe.Context, want)
+	}
+}
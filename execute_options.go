@@ -0,0 +1,83 @@
+package pipedream
+
+import (
+	"fmt"
+	"maps"
+)
+
+var ErrDataSourceAlreadyRegistered = fmt.Errorf("data source already registered")
+
+// ExecuteOption configures a single execution of a pipeline.
+type ExecuteOption func(*executeConfig)
+
+type executeConfig struct {
+	overrides  []DataSource
+	additional []DataSource
+
+	tracerSet      bool
+	tracerOverride Tracer
+}
+
+// WithDataSourceOverride replaces the registered data source with the same name for this execution only.
+// Execution fails with ErrDataSourceNotFound if no data source with that name is registered.
+func WithDataSourceOverride(source DataSource) ExecuteOption {
+	return func(c *executeConfig) {
+		c.overrides = append(c.overrides, source)
+	}
+}
+
+// WithAdditionalDataSources makes extra data sources available for this execution only.
+// Execution fails with ErrDataSourceAlreadyRegistered if one of them has the same name as a registered data source;
+// use WithDataSourceOverride to replace one instead.
+func WithAdditionalDataSources(sources ...DataSource) ExecuteOption {
+	return func(c *executeConfig) {
+		c.additional = append(c.additional, sources...)
+	}
+}
+
+// WithTracer sets the Tracer for this execution only, in place of the executor's. Use nil to disable tracing.
+func WithTracer(tracer Tracer) ExecuteOption {
+	return func(c *executeConfig) {
+		c.tracerSet = true
+		c.tracerOverride = tracer
+	}
+}
+
+func newExecuteConfig(opts []ExecuteOption) executeConfig {
+	var c executeConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// dataSources layers the execution's data sources on top of the registered ones.
+// The registered map is only copied if it needs to change, and is never modified.
+func (c executeConfig) dataSources(registered map[string]DataSource) (map[string]DataSource, error) {
+	if len(c.overrides) == 0 && len(c.additional) == 0 {
+		return registered, nil
+	}
+
+	sources := maps.Clone(registered)
+	for _, source := range c.additional {
+		if _, ok := sources[source.Name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDataSourceAlreadyRegistered, source.Name)
+		}
+		sources[source.Name] = source
+	}
+	for _, source := range c.overrides {
+		if _, ok := registered[source.Name]; !ok {
+			return nil, fmt.Errorf("%w: cannot override %s", ErrDataSourceNotFound, source.Name)
+		}
+		sources[source.Name] = source
+	}
+	return sources, nil
+}
+
+// tracer returns the tracer for the execution, falling back to the executor's.
+func (c executeConfig) tracer(fallback Tracer) Tracer {
+	if c.tracerSet {
+		return c.tracerOverride
+	}
+	return fallback
+}
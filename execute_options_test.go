@@ -0,0 +1,111 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// constant returns a data source that always responds with v.
+func constant(name string, v int) DataSource {
+	return NewDataSource(name, func(ctx context.Context, params int) (int, error) {
+		return v, nil
+	})
+}
+
+// queryPipeline returns a pipeline that returns the response of the named data source for 1.
+func queryPipeline(name string) Pipeline {
+	return Pipeline{Nodes: []Node{funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		v, err := Query[int, int](ectx, name, 1)
+		if err != nil {
+			return err
+		}
+		ectx.SetReturnValue(v)
+		return ErrPipelineExecutionStop
+	})}}
+}
+
+func TestWithDataSourceOverride(t *testing.T) {
+	executor := NewPipelineExecutor()
+	registered := constant("source", 1)
+	executor.RegisterDataSource(registered)
+
+	v, err := executor.Execute(context.Background(), queryPipeline("source"), WithDataSourceOverride(constant("source", 2)))
+	if err != nil || v != 2 {
+		t.Errorf("Execute with override = %v, %v, want 2, nil", v, err)
+	}
+	if v, err := executor.Execute(context.Background(), queryPipeline("source")); err != nil || v != 1 {
+		t.Errorf("Execute after override = %v, %v, want 1, nil", v, err)
+	}
+	if len(executor.ectx.dataSources) != 1 || executor.ectx.dataSources["source"].Name != "source" {
+		t.Errorf("registered data sources changed to %v", executor.ectx.dataSources)
+	}
+
+	_, err = executor.Execute(context.Background(), queryPipeline("source"), WithDataSourceOverride(constant("missing", 2)))
+	if !errors.Is(err, ErrDataSourceNotFound) {
+		t.Errorf("overriding a missing data source: got %v, want ErrDataSourceNotFound", err)
+	}
+}
+
+func TestWithAdditionalDataSources(t *testing.T) {
+	executor := NewPipelineExecutor()
+	executor.RegisterDataSource(constant("source", 1))
+
+	v, err := executor.Execute(context.Background(), queryPipeline("extra"), WithAdditionalDataSources(constant("extra", 3)))
+	if err != nil || v != 3 {
+		t.Errorf("Execute with additional data source = %v, %v, want 3, nil", v, err)
+	}
+	if _, err := executor.Execute(context.Background(), queryPipeline("extra")); !errors.Is(err, ErrDataSourceNotFound) {
+		t.Errorf("Execute after additional data source: got %v, want ErrDataSourceNotFound", err)
+	}
+	if _, ok := executor.ectx.dataSources["extra"]; ok {
+		t.Error("additional data source was registered on the executor")
+	}
+
+	_, err = executor.Execute(context.Background(), queryPipeline("source"), WithAdditionalDataSources(constant("source", 3)))
+	if !errors.Is(err, ErrDataSourceAlreadyRegistered) {
+		t.Errorf("adding a registered data source: got %v, want ErrDataSourceAlreadyRegistered", err)
+	}
+}
+
+func TestConcurrentOverrides(t *testing.T) {
+	executor := NewPipelineExecutor()
+	executor.RegisterDataSource(constant("source", -1))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			opts := []ExecuteOption{WithAdditionalDataSources(constant(fmt.Sprint("extra", i), i))}
+			if i%2 == 0 {
+				opts = append(opts, WithDataSourceOverride(constant("source", i)))
+			}
+
+			want := -1
+			if i%2 == 0 {
+				want = i
+			}
+			if v, err := executor.Execute(context.Background(), queryPipeline("source"), opts...); err != nil || v != want {
+				t.Errorf("execution %d = %v, %v, want %d, nil", i, v, err, want)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(executor.ectx.dataSources) != 1 {
+		t.Errorf("registered data sources changed to %v", executor.ectx.dataSources)
+	}
+}
+
+func TestValidateWithExecuteOptions(t *testing.T) {
+	executor := NewPipelineExecutor()
+	executor.RegisterDataSource(constant("source", 1))
+
+	diags := executor.Validate(Pipeline{}, WithAdditionalDataSources(constant("source", 2)))
+	if len(diags) != 1 || !errors.Is(diags[0], ErrDataSourceAlreadyRegistered) {
+		t.Errorf("Validate = %v, want ErrDataSourceAlreadyRegistered", diags)
+	}
+}
@@ -0,0 +1,149 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthChecker is implemented by anything that can report on the health of a data source's dependency.
+// Add one to a data source with WithHealthCheck, or implement it on Middleware to have it checked automatically.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckFunc adapts a function to the HealthChecker interface.
+type HealthCheckFunc func(ctx context.Context) error
+
+func (f HealthCheckFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// WithHealthCheck adds a health check to the data source.
+func WithHealthCheck(checker HealthChecker) DataSourceOption {
+	return func(c *dataSourceConfig) {
+		c.healthChecks = append(c.healthChecks, checker)
+	}
+}
+
+// HasHealthChecks reports whether the data source has any health checks.
+func (d DataSource) HasHealthChecks() bool {
+	return len(d.healthChecks) > 0
+}
+
+// CheckHealth runs every health check of the data source, returning all of their errors.
+// A data source without health checks is assumed to be healthy.
+func (d DataSource) CheckHealth(ctx context.Context) error {
+	var errs []error
+	for _, checker := range d.healthChecks {
+		if err := checker.CheckHealth(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wrappers returns the names of the layers wrapped around the data source's getter, outermost first.
+func (d DataSource) Wrappers() []string {
+	return slices.Clone(d.wrappers)
+}
+
+// DataSourceInfo describes a registered data source.
+type DataSourceInfo struct {
+	Name         string
+	ParamType    reflect.Type
+	ResponseType reflect.Type
+	Stream       bool
+
+	// Wrappers are the names of the layers wrapped around the getter, outermost first.
+	Wrappers []string
+
+	HasHealthChecks bool
+}
+
+// ListDataSources describes every registered data source, sorted by name.
+func (p *PipelineExecutor) ListDataSources() []DataSourceInfo {
+	infos := make([]DataSourceInfo, 0, len(p.ectx.dataSources))
+	for _, d := range p.ectx.dataSources {
+		infos = append(infos, DataSourceInfo{
+			Name:            d.Name,
+			ParamType:       d.paramType,
+			ResponseType:    d.responseType,
+			Stream:          d.IsStream(),
+			Wrappers:        d.Wrappers(),
+			HasHealthChecks: d.HasHealthChecks(),
+		})
+	}
+	slices.SortFunc(infos, func(a, b DataSourceInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return infos
+}
+
+// HealthReport is the result of checking the health of every registered data source.
+type HealthReport struct {
+	// Healthy is true if every data source is healthy.
+	Healthy bool
+
+	// Sources holds the result for each data source, sorted by name.
+	Sources []DataSourceHealth
+}
+
+// DataSourceHealth is the result of checking the health of a single data source.
+type DataSourceHealth struct {
+	Name string
+
+	// Checked is false if the data source has no health checks.
+	Checked bool
+
+	// Err is nil if the data source is healthy.
+	Err error
+
+	Duration time.Duration
+}
+
+// CheckHealth checks the health of every registered data source concurrently.
+// Use a context with a deadline to bound how long slow checks may take.
+func (p *PipelineExecutor) CheckHealth(ctx context.Context) HealthReport {
+	sources := make([]DataSource, 0, len(p.ectx.dataSources))
+	for _, d := range p.ectx.dataSources {
+		sources = append(sources, d)
+	}
+	slices.SortFunc(sources, func(a, b DataSource) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	report := HealthReport{
+		Healthy: true,
+		Sources: make([]DataSourceHealth, len(sources)),
+	}
+
+	var wg sync.WaitGroup
+	for i, d := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := d.CheckHealth(ctx)
+			report.Sources[i] = DataSourceHealth{
+				Name:     d.Name,
+				Checked:  d.HasHealthChecks(),
+				Err:      err,
+				Duration: time.Since(start),
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, h := range report.Sources {
+		if h.Err != nil {
+			report.Healthy = false
+		}
+	}
+	return report
+}
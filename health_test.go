@@ -0,0 +1,106 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListDataSources(t *testing.T) {
+	executor := NewPipelineExecutor()
+	executor.RegisterDataSource(NewDataSource("double", double, WithRetry(2, ConstantBackoff(0), nil), WithTimeout(time.Second)).
+		WithMiddleware(NewCircuitBreaker(CircuitBreakerConfig{}), NewBulkhead(BulkheadConfig{MaxConcurrent: 3})))
+	executor.RegisterDataSource(NewStreamDataSource("numbers", func(ctx context.Context, params string) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {}
+	}))
+
+	want := []DataSourceInfo{
+		{
+			Name:            "double",
+			ParamType:       reflect.TypeFor[int](),
+			ResponseType:    reflect.TypeFor[int](),
+			Wrappers:        []string{"CircuitBreaker", "Bulkhead(3)", "Retry(2)", "Timeout(1s)"},
+			HasHealthChecks: true,
+		},
+		{
+			Name:         "numbers",
+			ParamType:    reflect.TypeFor[string](),
+			ResponseType: reflect.TypeFor[iter.Seq2[int, error]](),
+			Stream:       true,
+		},
+	}
+	if got := executor.ListDataSources(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListDataSources = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	errDown := errors.New("down")
+	started := make(chan struct{})
+
+	executor := NewPipelineExecutor()
+	executor.RegisterDataSource(NewDataSource("unchecked", double))
+	executor.RegisterDataSource(NewDataSource("healthy", double, WithHealthCheck(HealthCheckFunc(func(ctx context.Context) error {
+		return nil
+	}))))
+	executor.RegisterDataSource(NewDataSource("failing", double,
+		WithHealthCheck(HealthCheckFunc(func(ctx context.Context) error { return errDown })),
+		WithHealthCheck(HealthCheckFunc(func(ctx context.Context) error { return nil })),
+	))
+	// slow blocks until its context is canceled, which happens once it started.
+	executor.RegisterDataSource(NewDataSource("slow", double, WithHealthCheck(HealthCheckFunc(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	report := executor.CheckHealth(ctx)
+
+	if report.Healthy {
+		t.Error("report is healthy, want unhealthy")
+	}
+	want := []struct {
+		name    string
+		checked bool
+		err     error
+	}{
+		{"failing", true, errDown},
+		{"healthy", true, nil},
+		{"slow", true, context.Canceled},
+		{"unchecked", false, nil},
+	}
+	if len(report.Sources) != len(want) {
+		t.Fatalf("got %d sources, want %d", len(report.Sources), len(want))
+	}
+	for i, w := range want {
+		h := report.Sources[i]
+		if h.Name != w.name || h.Checked != w.checked || !errors.Is(h.Err, w.err) || (w.err == nil) != (h.Err == nil) {
+			t.Errorf("source %d = %+v, want %s checked %v with error %v", i, h, w.name, w.checked, w.err)
+		}
+	}
+}
+
+func TestCheckHealthCircuitBreaker(t *testing.T) {
+	executor := NewPipelineExecutor()
+	source := NewDataSource("failing", func(ctx context.Context, params int) (int, error) {
+		return 0, errNode
+	}).WithMiddleware(NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 1}))
+	executor.RegisterDataSource(source)
+
+	if report := executor.CheckHealth(context.Background()); !report.Healthy {
+		t.Errorf("report = %+v, want healthy with a closed circuit", report)
+	}
+	source.Get(context.Background(), 1)
+	report := executor.CheckHealth(context.Background())
+	if report.Healthy || !errors.Is(report.Sources[0].Err, ErrCircuitOpen) {
+		t.Errorf("report = %+v, want unhealthy with ErrCircuitOpen", report)
+	}
+}
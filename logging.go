@@ -0,0 +1,221 @@
+package pipedream
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// LogConfig configures the logs a PipelineExecutor emits.
+// Levels are slog.Levelers, so a slog.Level or a *slog.LevelVar can be used.
+type LogConfig struct {
+	// NodeLevel is the level for the start and finish of pipelines and nodes. Default slog.LevelDebug.
+	NodeLevel slog.Leveler
+
+	// DataSourceLevel is the level for data source calls. Default slog.LevelDebug.
+	DataSourceLevel slog.Leveler
+
+	// DecisionLevel is the level for condition results, branch decisions and filter counts. Default slog.LevelDebug.
+	DecisionLevel slog.Leveler
+
+	// ErrorLevel is the level for anything that finished with an error. Default slog.LevelError.
+	ErrorLevel slog.Leveler
+
+	// IncludeContext adds the pipeline context values to the log of each finished node.
+	// Values of keys marked sensitive with PipelineContext.MarkSensitive or listed in SensitiveKeys are redacted.
+	IncludeContext bool
+
+	// SensitiveKeys are context keys that are always redacted, from execution reports
+	// and PipelineError contexts too. Fields of data source params with these names are redacted from reports.
+	// They apply even if the logger is nil.
+	SensitiveKeys []string
+}
+
+type executionLogger struct {
+	logger *slog.Logger
+	config LogConfig
+}
+
+// SetLogger sets the logger every execution logs to. Use nil to disable logging.
+func (p *PipelineExecutor) SetLogger(logger *slog.Logger, config LogConfig) {
+	p.ectx.sensitive = slices.Clone(config.SensitiveKeys)
+	if logger == nil {
+		p.ectx.logger = nil
+		return
+	}
+	if config.NodeLevel == nil {
+		config.NodeLevel = slog.LevelDebug
+	}
+	if config.DataSourceLevel == nil {
+		config.DataSourceLevel = slog.LevelDebug
+	}
+	if config.DecisionLevel == nil {
+		config.DecisionLevel = slog.LevelDebug
+	}
+	if config.ErrorLevel == nil {
+		config.ErrorLevel = slog.LevelError
+	}
+	p.ectx.logger = &executionLogger{logger: logger, config: config}
+}
+
+// ExecutionID returns the ID of the current execution, which is unique per call to PipelineExecutor.Execute.
+func (e ExecutionContext) ExecutionID() string {
+	return e.executionID
+}
+
+// Logger returns a logger annotated with the pipeline name, execution ID and node path.
+// If the executor has no logger, log records are discarded.
+func (e ExecutionContext) Logger() *slog.Logger {
+	if e.logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return e.logger.logger.With(e.logAttrs()...)
+}
+
+// LogDecision logs a decision made by a node, such as which branch was taken, at the configured DecisionLevel.
+func (e ExecutionContext) LogDecision(msg string, args ...any) {
+	if e.logger == nil {
+		return
+	}
+	e.log(e.logger.config.DecisionLevel.Level(), msg, args...)
+}
+
+func (e ExecutionContext) log(level slog.Level, msg string, args ...any) {
+	logger := e.logger.logger
+	ctx := e.Context()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.With(e.logAttrs()...).Log(ctx, level, msg, args...)
+}
+
+func (e ExecutionContext) logAttrs() []any {
+	attrs := []any{slog.String("execution_id", e.executionID)}
+	if e.pipelineName != "" {
+		attrs = append(attrs, slog.String("pipeline", e.pipelineName))
+	}
+	if e.path != "" {
+		attrs = append(attrs, slog.String("path", e.path))
+	}
+	return attrs
+}
+
+// logSpanStart logs the start of pipelines and nodes.
+func (e ExecutionContext) logSpanStart(info SpanInfo) {
+	if e.logger == nil {
+		return
+	}
+	switch info.Kind {
+	case SpanPipeline:
+		e.log(e.logger.config.NodeLevel.Level(), "pipeline started")
+	case SpanNode:
+		e.log(e.logger.config.NodeLevel.Level(), "node started", slog.String("node", info.Name))
+	}
+}
+
+// logSpanEnd logs how a span ended.
+func (e ExecutionContext) logSpanEnd(info SpanInfo, result SpanResult, pctx *PipelineContext) {
+	if e.logger == nil {
+		return
+	}
+	config := e.logger.config
+
+	var level slog.Level
+	var msg string
+	args := []any{slog.Duration("duration", result.Duration)}
+	switch info.Kind {
+	case SpanPipeline:
+		level, msg = config.NodeLevel.Level(), "pipeline finished"
+		args = append(args, slog.Bool("stopped", result.Stopped))
+	case SpanNode:
+		level, msg = config.NodeLevel.Level(), "node finished"
+		args = append(args, slog.String("node", info.Name), slog.Bool("stopped", result.Stopped))
+		if config.IncludeContext && pctx != nil {
+			args = append(args, e.contextAttr(*pctx))
+		}
+	case SpanCondition:
+		level, msg = config.DecisionLevel.Level(), "condition evaluated"
+		args = append(args, slog.String("condition", info.Name), slog.Bool("result", result.Result))
+	case SpanDataSource:
+		level, msg = config.DataSourceLevel.Level(), "data source called"
+		args = append(args, slog.String("data_source", info.Name))
+		if result.Size >= 0 {
+			args = append(args, slog.Int("size", result.Size))
+		}
+	}
+	if result.Err != nil {
+		level = config.ErrorLevel.Level()
+		args = append(args, slog.Any("error", result.Err))
+	}
+	e.log(level, msg, args...)
+}
+
+// contextAttr groups the context values, redacting sensitive ones.
+func (e ExecutionContext) contextAttr(pctx PipelineContext) slog.Attr {
+	snapshot := e.redact(pctx.Snapshot())
+	attrs := make([]any, 0, len(snapshot))
+	for _, k := range slices.Sorted(maps.Keys(snapshot)) {
+		attrs = append(attrs, slog.Any(k, snapshot[k]))
+	}
+	return slog.Group("context", attrs...)
+}
+
+// redact replaces the values of SensitiveKeys in a context snapshot with RedactedValue.
+func (e ExecutionContext) redact(snapshot map[string]any) map[string]any {
+	for _, k := range e.sensitive {
+		if _, ok := snapshot[k]; ok {
+			snapshot[k] = RedactedValue
+		}
+	}
+	return snapshot
+}
+
+// redactParams returns the data source params with the fields or map entries named in SensitiveKeys
+// replaced by RedactedValue. Params with such fields are returned as a map[string]any.
+func (e ExecutionContext) redactParams(params any) any {
+	if len(e.sensitive) == 0 {
+		return params
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	fields := map[string]any{}
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		iter := v.MapRange()
+		for iter.Next() {
+			fields[iter.Key().String()] = iter.Value().Interface()
+		}
+	case v.Kind() == reflect.Struct:
+		for i := range v.NumField() {
+			if f := v.Type().Field(i); f.IsExported() {
+				fields[f.Name] = v.Field(i).Interface()
+			}
+		}
+	default:
+		return params
+	}
+
+	redacted := false
+	for _, k := range e.sensitive {
+		if _, ok := fields[k]; ok {
+			fields[k] = RedactedValue
+			redacted = true
+		}
+	}
+	if !redacted {
+		return params
+	}
+	return fields
+}
+
+func newExecutionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,146 @@
+package pipedream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+// captureLogs returns a logger writing JSON records at or above the level, and a function decoding them.
+func captureLogs(t *testing.T, level slog.Leveler) (*slog.Logger, func() []map[string]any) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+	return logger, func() []map[string]any {
+		var records []map[string]any
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var r map[string]any
+			if err := dec.Decode(&r); err != nil {
+				t.Fatalf("decoding log record: %v", err)
+			}
+			records = append(records, r)
+		}
+		return records
+	}
+}
+
+func setSecret(ectx ExecutionContext, pctx PipelineContext) error {
+	pctx.SetValue("token", "secret")
+	return nil
+}
+
+func TestLogEvents(t *testing.T) {
+	logger, records := captureLogs(t, slog.LevelDebug)
+	executor := NewPipelineExecutor()
+	executor.SetLogger(logger, LogConfig{IncludeContext: true, SensitiveKeys: []string{"token"}})
+	executor.RegisterDataSource(NewDataSource("double", double))
+
+	query := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		if _, err := Query[int, int](ectx, "double", 1); err != nil {
+			return err
+		}
+		_, err := ectx.EvaluateCondition(&ValueCondition{LHS: LiteralValue[int]{Value: 1}, RHS: LiteralValue[int]{Value: 1}, Operand: ConditionEqual}, pctx)
+		return err
+	})
+	p := Pipeline{Name: "p", Nodes: []Node{funcNode(setSecret), query}}
+	if _, err := executor.Execute(context.Background(), p); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	want := []struct{ msg, path string }{
+		{"pipeline started", ""},
+		{"node started", "nodes[0]"},
+		{"node finished", "nodes[0]"},
+		{"node started", "nodes[1]"},
+		{"data source called", "nodes[1]"},
+		{"condition evaluated", "nodes[1].Condition"},
+		{"node finished", "nodes[1]"},
+		{"pipeline finished", ""},
+	}
+	got := records()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %v", len(got), len(want), got)
+	}
+	id, _ := got[0]["execution_id"].(string)
+	if id == "" {
+		t.Error("records have no execution ID")
+	}
+	for i, w := range want {
+		r := got[i]
+		path, _ := r["path"].(string)
+		if r["msg"] != w.msg || path != w.path || r["pipeline"] != "p" || r["execution_id"] != id || r["level"] != "DEBUG" {
+			t.Errorf("record %d = %v, want %q at DEBUG with path %q, pipeline p and execution ID %v", i, r, w.msg, w.path, id)
+		}
+	}
+
+	if r := got[4]; r["data_source"] != "double" {
+		t.Errorf("data source record = %v, want data_source double", r)
+	}
+	if r := got[5]; r["result"] != true {
+		t.Errorf("condition record = %v, want result true", r)
+	}
+	context, _ := got[2]["context"].(map[string]any)
+	if context["token"] != RedactedValue {
+		t.Errorf("logged context = %v, want token redacted", got[2]["context"])
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelInfo)
+	logger, records := captureLogs(t, level)
+	executor := NewPipelineExecutor()
+	executor.SetLogger(logger, LogConfig{NodeLevel: slog.LevelInfo, ErrorLevel: slog.LevelWarn})
+	executor.RegisterDataSource(NewDataSource("double", double))
+
+	query := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		_, err := Query[int, int](ectx, "double", 1)
+		return err
+	})
+	executor.Execute(context.Background(), Pipeline{Nodes: []Node{query, failNode{}}})
+
+	var levels []string
+	for _, r := range records() {
+		if r["msg"] == "data source called" {
+			t.Errorf("data source call logged at %v, want it below the handler's level", r["level"])
+		}
+		levels = append(levels, r["level"].(string))
+	}
+	// The failing node and the pipeline finish at ErrorLevel.
+	want := []string{"INFO", "INFO", "INFO", "INFO", "WARN", "WARN"}
+	if !slices.Equal(levels, want) {
+		t.Errorf("levels = %v, want %v", levels, want)
+	}
+
+	level.Set(slog.LevelWarn)
+	executor.Execute(context.Background(), Pipeline{Nodes: []Node{query}})
+	if got := records(); len(got) != 0 {
+		t.Errorf("got %d records after raising the level, want none", len(got))
+	}
+}
+
+func TestSensitiveKeysRedactedFromReportsAndErrors(t *testing.T) {
+	executor := NewPipelineExecutor()
+	executor.SetLogger(nil, LogConfig{SensitiveKeys: []string{"token"}})
+
+	fail := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		pctx.GetValue("token")
+		return errNode
+	})
+	_, report, err := executor.ExecuteWithReport(context.Background(), Pipeline{Nodes: []Node{funcNode(setSecret), fail}})
+
+	var pe *PipelineError
+	if !errors.As(err, &pe) {
+		t.Fatalf("got %v, want a PipelineError", err)
+	}
+	if v, ok := pe.Context["token"]; !ok || v != RedactedValue {
+		t.Errorf("PipelineError.Context = %v, want token redacted", pe.Context)
+	}
+	if v := report.Context["token"]; v != RedactedValue {
+		t.Errorf("Report.Context = %v, want token redacted", report.Context)
+	}
+}
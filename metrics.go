@@ -0,0 +1,418 @@
+package pipedream
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Names of the metrics reported to a MetricsSink. Every metric has a "pipeline" label with the pipeline name.
+// Durations are in seconds.
+const (
+	// MetricExecutions counts executions, with an "outcome" label of "success", "stopped" or "error".
+	MetricExecutions = "pipedream_executions_total"
+
+	// MetricExecutionDuration observes the duration of executions, with an "outcome" label.
+	MetricExecutionDuration = "pipedream_execution_duration_seconds"
+
+	// MetricNodeDuration observes the duration of nodes, with a "node" label holding the node type.
+	MetricNodeDuration = "pipedream_node_duration_seconds"
+
+	// MetricDataSourceCalls counts data source calls, with a "data_source" label.
+	MetricDataSourceCalls = "pipedream_data_source_calls_total"
+
+	// MetricDataSourceErrors counts data source calls that failed, with a "data_source" label.
+	MetricDataSourceErrors = "pipedream_data_source_errors_total"
+
+	// MetricDataSourceDuration observes the duration of data source calls, with a "data_source" label.
+	MetricDataSourceDuration = "pipedream_data_source_duration_seconds"
+
+	// MetricFilterSelectivity observes the fraction of elements a FilterNode keeps, between 0 and 1.
+	MetricFilterSelectivity = "pipedream_filter_selectivity"
+
+	// MetricFoldElements observes the number of elements a FoldNode aggregates.
+	MetricFoldElements = "pipedream_fold_elements"
+)
+
+// metricHelp holds the HELP text WritePrometheus writes for the built-in metrics.
+var metricHelp = map[string]string{
+	MetricExecutions:         "Number of pipeline executions by outcome.",
+	MetricExecutionDuration:  "Duration of pipeline executions in seconds.",
+	MetricNodeDuration:       "Duration of node executions in seconds.",
+	MetricDataSourceCalls:    "Number of data source calls.",
+	MetricDataSourceErrors:   "Number of data source calls that failed.",
+	MetricDataSourceDuration: "Duration of data source calls in seconds.",
+	MetricFilterSelectivity:  "Fraction of elements kept by filter nodes.",
+	MetricFoldElements:       "Number of elements aggregated by fold nodes.",
+}
+
+// MetricsSink receives counters and histogram observations from executions.
+// Implementations must be safe for concurrent use. Labels must not be modified or retained without copying.
+type MetricsSink interface {
+	// Count adds delta to a counter.
+	Count(name string, labels map[string]string, delta float64)
+
+	// Observe records a value in a histogram.
+	Observe(name string, labels map[string]string, value float64)
+}
+
+// SetMetricsSink sets the sink that receives metrics for every execution. Use nil to disable metrics.
+func (p *PipelineExecutor) SetMetricsSink(sink MetricsSink) {
+	p.ectx.metrics = sink
+}
+
+// CountMetric adds delta to the named counter, if the executor has a MetricsSink.
+// Labels are given as alternating names and values; the "pipeline" label is added automatically.
+func (e ExecutionContext) CountMetric(name string, delta float64, labels ...string) {
+	if e.metrics == nil {
+		return
+	}
+	e.metrics.Count(name, e.metricLabels(labels), delta)
+}
+
+// ObserveMetric records a value in the named histogram, if the executor has a MetricsSink.
+// Labels are given as alternating names and values; the "pipeline" label is added automatically.
+func (e ExecutionContext) ObserveMetric(name string, value float64, labels ...string) {
+	if e.metrics == nil {
+		return
+	}
+	e.metrics.Observe(name, e.metricLabels(labels), value)
+}
+
+func (e ExecutionContext) metricLabels(labels []string) map[string]string {
+	m := make(map[string]string, len(labels)/2+1)
+	m["pipeline"] = e.pipelineName
+	for i := 0; i+1 < len(labels); i += 2 {
+		m[labels[i]] = labels[i+1]
+	}
+	return m
+}
+
+// recordSpanMetrics reports the metrics for an ended span.
+func (e ExecutionContext) recordSpanMetrics(info SpanInfo, result SpanResult) {
+	if e.metrics == nil {
+		return
+	}
+
+	seconds := result.Duration.Seconds()
+	switch info.Kind {
+	case SpanPipeline:
+		outcome := "success"
+		if result.Err != nil {
+			outcome = "error"
+		} else if result.Stopped {
+			outcome = "stopped"
+		}
+		e.CountMetric(MetricExecutions, 1, "outcome", outcome)
+		e.ObserveMetric(MetricExecutionDuration, seconds, "outcome", outcome)
+	case SpanNode:
+		e.ObserveMetric(MetricNodeDuration, seconds, "node", info.Name)
+	case SpanDataSource:
+		e.CountMetric(MetricDataSourceCalls, 1, "data_source", info.Name)
+		if result.Err != nil {
+			e.CountMetric(MetricDataSourceErrors, 1, "data_source", info.Name)
+		}
+		e.ObserveMetric(MetricDataSourceDuration, seconds, "data_source", info.Name)
+	}
+}
+
+// MetricsAggregatorConfig configures a MetricsAggregator.
+type MetricsAggregatorConfig struct {
+	// MaxSamples is the number of most recent observations per histogram that percentiles are computed from.
+	// Counts and sums always cover every observation. Default 1024.
+	MaxSamples int
+
+	// Quantiles are the percentiles reported by Summaries and WritePrometheus. Default 0.5, 0.9 and 0.99.
+	Quantiles []float64
+}
+
+// MetricsAggregator is a MetricsSink that aggregates metrics in memory.
+// Histograms are summarized with percentiles over a window of recent observations.
+type MetricsAggregator struct {
+	config MetricsAggregatorConfig
+
+	mu         sync.Mutex
+	counters   map[metricKey]*counterMetric
+	histograms map[metricKey]*histogramMetric
+}
+
+type metricKey struct {
+	name   string
+	labels string // Labels in Prometheus format, sorted by name.
+}
+
+type counterMetric struct {
+	labels map[string]string
+	value  float64
+}
+
+type histogramMetric struct {
+	labels  map[string]string
+	count   int
+	sum     float64
+	min     float64
+	max     float64
+	samples []float64 // Ring buffer of the most recent observations.
+	next    int
+}
+
+// MetricValue is the value of a counter with a particular set of labels.
+type MetricValue struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Summary summarizes the observations of a histogram with a particular set of labels.
+type Summary struct {
+	Name   string
+	Labels map[string]string
+
+	Count int
+	Sum   float64
+	Min   float64
+	Max   float64
+
+	// Quantiles maps each configured quantile to its value over the recent observations.
+	Quantiles map[float64]float64
+}
+
+// NewMetricsAggregator creates an empty MetricsAggregator.
+func NewMetricsAggregator(config MetricsAggregatorConfig) *MetricsAggregator {
+	if config.MaxSamples <= 0 {
+		config.MaxSamples = 1024
+	}
+	if len(config.Quantiles) == 0 {
+		config.Quantiles = []float64{0.5, 0.9, 0.99}
+	}
+	return &MetricsAggregator{
+		config:     config,
+		counters:   map[metricKey]*counterMetric{},
+		histograms: map[metricKey]*histogramMetric{},
+	}
+}
+
+// Count implements the MetricsSink interface for MetricsAggregator.
+func (a *MetricsAggregator) Count(name string, labels map[string]string, delta float64) {
+	key := metricKey{name: name, labels: formatLabels(labels, "")}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c, ok := a.counters[key]
+	if !ok {
+		c = &counterMetric{labels: maps.Clone(labels)}
+		a.counters[key] = c
+	}
+	c.value += delta
+}
+
+// Observe implements the MetricsSink interface for MetricsAggregator.
+func (a *MetricsAggregator) Observe(name string, labels map[string]string, value float64) {
+	key := metricKey{name: name, labels: formatLabels(labels, "")}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	h, ok := a.histograms[key]
+	if !ok {
+		h = &histogramMetric{labels: maps.Clone(labels), min: value, max: value}
+		a.histograms[key] = h
+	}
+	h.count++
+	h.sum += value
+	h.min = min(h.min, value)
+	h.max = max(h.max, value)
+	if len(h.samples) < a.config.MaxSamples {
+		h.samples = append(h.samples, value)
+	} else {
+		h.samples[h.next] = value
+		h.next = (h.next + 1) % a.config.MaxSamples
+	}
+}
+
+// Counter returns the value of the counter with exactly the given labels, or 0 if it has not been counted.
+func (a *MetricsAggregator) Counter(name string, labels map[string]string) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if c, ok := a.counters[metricKey{name: name, labels: formatLabels(labels, "")}]; ok {
+		return c.value
+	}
+	return 0
+}
+
+// Counters returns every counter, sorted by name and then labels.
+func (a *MetricsAggregator) Counters() []MetricValue {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	values := make([]MetricValue, 0, len(a.counters))
+	for _, key := range sortedKeys(a.counters) {
+		c := a.counters[key]
+		values = append(values, MetricValue{Name: key.name, Labels: maps.Clone(c.labels), Value: c.value})
+	}
+	return values
+}
+
+// Summary returns the summary of the histogram with exactly the given labels.
+// The second return value is false if nothing has been observed.
+func (a *MetricsAggregator) Summary(name string, labels map[string]string) (Summary, bool) {
+	key := metricKey{name: name, labels: formatLabels(labels, "")}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	h, ok := a.histograms[key]
+	if !ok {
+		return Summary{}, false
+	}
+	return a.summarize(key, h), true
+}
+
+// Summaries returns the summary of every histogram, sorted by name and then labels.
+func (a *MetricsAggregator) Summaries() []Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	summaries := make([]Summary, 0, len(a.histograms))
+	for _, key := range sortedKeys(a.histograms) {
+		summaries = append(summaries, a.summarize(key, a.histograms[key]))
+	}
+	return summaries
+}
+
+// Reset discards all metrics.
+func (a *MetricsAggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counters = map[metricKey]*counterMetric{}
+	a.histograms = map[metricKey]*histogramMetric{}
+}
+
+func (a *MetricsAggregator) summarize(key metricKey, h *histogramMetric) Summary {
+	sorted := slices.Clone(h.samples)
+	slices.Sort(sorted)
+
+	quantiles := make(map[float64]float64, len(a.config.Quantiles))
+	for _, q := range a.config.Quantiles {
+		quantiles[q] = quantile(sorted, q)
+	}
+	return Summary{
+		Name:      key.name,
+		Labels:    maps.Clone(h.labels),
+		Count:     h.count,
+		Sum:       h.sum,
+		Min:       h.min,
+		Max:       h.max,
+		Quantiles: quantiles,
+	}
+}
+
+// WritePrometheus writes every metric in the Prometheus text exposition format.
+// Counters are written as counters and histograms as summaries with the configured quantiles.
+// Built-in metrics are preceded by a HELP line.
+func (a *MetricsAggregator) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var lastName string
+	for _, c := range a.Counters() {
+		if c.Name != lastName {
+			writeMetricHeader(bw, c.Name, "counter")
+			lastName = c.Name
+		}
+		fmt.Fprintf(bw, "%s%s %s\n", c.Name, formatLabels(c.Labels, ""), formatFloat(c.Value))
+	}
+
+	lastName = ""
+	for _, s := range a.Summaries() {
+		if s.Name != lastName {
+			writeMetricHeader(bw, s.Name, "summary")
+			lastName = s.Name
+		}
+		for _, q := range slices.Sorted(maps.Keys(s.Quantiles)) {
+			fmt.Fprintf(bw, "%s%s %s\n", s.Name, formatLabels(s.Labels, formatFloat(q)), formatFloat(s.Quantiles[q]))
+		}
+		labels := formatLabels(s.Labels, "")
+		fmt.Fprintf(bw, "%s_sum%s %s\n", s.Name, labels, formatFloat(s.Sum))
+		fmt.Fprintf(bw, "%s_count%s %d\n", s.Name, labels, s.Count)
+	}
+
+	return bw.Flush()
+}
+
+func writeMetricHeader(w io.Writer, name string, typ string) {
+	if help, ok := metricHelp[name]; ok {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// quantile returns the q-quantile of sorted values using the nearest-rank method, or NaN if there are none.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// formatLabels formats labels in Prometheus format, sorted by name, e.g. `{a="1",b="2"}`.
+// If q is not empty, a quantile label is added last.
+func formatLabels(labels map[string]string, q string) string {
+	if len(labels) == 0 && q == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range slices.Sorted(maps.Keys(labels)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", k, escapeLabelValue(labels[k]))
+	}
+	if q != "" {
+		if len(labels) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "quantile=\"%s\"", q)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+func sortedKeys[V any](m map[metricKey]V) []metricKey {
+	return slices.SortedFunc(maps.Keys(m), func(a, b metricKey) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.labels, b.labels)
+	})
+}
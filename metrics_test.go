@@ -0,0 +1,158 @@
+package pipedream
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetricsAggregatorCounters(t *testing.T) {
+	a := NewMetricsAggregator(MetricsAggregatorConfig{})
+	a.Count("calls", map[string]string{"source": "b"}, 1)
+	a.Count("calls", map[string]string{"source": "a"}, 2)
+	a.Count("calls", map[string]string{"source": "a"}, 0.5)
+	a.Count("aborts", nil, 1)
+
+	if v := a.Counter("calls", map[string]string{"source": "a"}); v != 2.5 {
+		t.Errorf("Counter = %v, want 2.5", v)
+	}
+	if v := a.Counter("calls", nil); v != 0 {
+		t.Errorf("Counter without labels = %v, want 0", v)
+	}
+
+	want := []MetricValue{
+		{Name: "aborts", Labels: nil, Value: 1},
+		{Name: "calls", Labels: map[string]string{"source": "a"}, Value: 2.5},
+		{Name: "calls", Labels: map[string]string{"source": "b"}, Value: 1},
+	}
+	if got := a.Counters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Counters = %+v, want %+v", got, want)
+	}
+
+	a.Reset()
+	if got := a.Counters(); len(got) != 0 {
+		t.Errorf("Counters after Reset = %+v, want none", got)
+	}
+}
+
+func TestMetricsAggregatorSummaries(t *testing.T) {
+	a := NewMetricsAggregator(MetricsAggregatorConfig{})
+	// Observe 1 to 100 out of order.
+	for i := range 100 {
+		a.Observe("latency", nil, float64((i*37)%100+1))
+	}
+
+	s, ok := a.Summary("latency", nil)
+	if !ok {
+		t.Fatal("Summary not found")
+	}
+	if s.Count != 100 || s.Sum != 5050 || s.Min != 1 || s.Max != 100 {
+		t.Errorf("Summary = %+v, want count 100, sum 5050, min 1, max 100", s)
+	}
+	if want := map[float64]float64{0.5: 50, 0.9: 90, 0.99: 99}; !reflect.DeepEqual(s.Quantiles, want) {
+		t.Errorf("Quantiles = %v, want %v", s.Quantiles, want)
+	}
+
+	if _, ok := a.Summary("latency", map[string]string{"a": "b"}); ok {
+		t.Error("Summary found for labels never observed")
+	}
+}
+
+func TestMetricsAggregatorMaxSamples(t *testing.T) {
+	a := NewMetricsAggregator(MetricsAggregatorConfig{MaxSamples: 3, Quantiles: []float64{0, 1}})
+	for _, v := range []float64{5, 1, 4, 3, 2} {
+		a.Observe("latency", nil, v)
+	}
+
+	// Counts, sums and extremes cover every observation, quantiles only the last three.
+	s, _ := a.Summary("latency", nil)
+	if s.Count != 5 || s.Sum != 15 || s.Min != 1 || s.Max != 5 {
+		t.Errorf("Summary = %+v, want count 5, sum 15, min 1, max 5", s)
+	}
+	if want := map[float64]float64{0: 2, 1: 4}; !reflect.DeepEqual(s.Quantiles, want) {
+		t.Errorf("Quantiles = %v, want %v", s.Quantiles, want)
+	}
+}
+
+func TestExecutionMetrics(t *testing.T) {
+	a := NewMetricsAggregator(MetricsAggregatorConfig{})
+	executor := NewPipelineExecutor()
+	executor.SetMetricsSink(a)
+	executor.RegisterDataSource(NewDataSource("double", func(ctx context.Context, params int) (int, error) {
+		if params < 0 {
+			return 0, errNode
+		}
+		return params * 2, nil
+	}))
+
+	query := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		Query[int, int](ectx, "double", 1)
+		_, err := Query[int, int](ectx, "double", -1)
+		return err
+	})
+	executor.Execute(context.Background(), Pipeline{Name: "ok", Nodes: []Node{setNode{"a"}}})
+	executor.Execute(context.Background(), Pipeline{Name: "bad", Nodes: []Node{query}})
+
+	counters := map[string]float64{
+		MetricExecutions:       a.Counter(MetricExecutions, map[string]string{"pipeline": "ok", "outcome": "success"}),
+		MetricDataSourceCalls:  a.Counter(MetricDataSourceCalls, map[string]string{"pipeline": "bad", "data_source": "double"}),
+		MetricDataSourceErrors: a.Counter(MetricDataSourceErrors, map[string]string{"pipeline": "bad", "data_source": "double"}),
+	}
+	want := map[string]float64{MetricExecutions: 1, MetricDataSourceCalls: 2, MetricDataSourceErrors: 1}
+	if !reflect.DeepEqual(counters, want) {
+		t.Errorf("counters = %v, want %v", counters, want)
+	}
+	if v := a.Counter(MetricExecutions, map[string]string{"pipeline": "bad", "outcome": "error"}); v != 1 {
+		t.Errorf("failed executions = %v, want 1", v)
+	}
+
+	var nodes int
+	for _, s := range a.Summaries() {
+		if s.Name == MetricNodeDuration {
+			nodes += s.Count
+		}
+	}
+	if nodes != 2 {
+		t.Errorf("observed %d node durations, want 2", nodes)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	a := NewMetricsAggregator(MetricsAggregatorConfig{Quantiles: []float64{0.5, 1}})
+	a.Count(MetricDataSourceCalls, map[string]string{"pipeline": "p", "data_source": "users"}, 3)
+	a.Count(MetricDataSourceCalls, map[string]string{"pipeline": `say "hi"\now` + "\n", "data_source": "users"}, 1)
+	a.Count("custom_total", nil, 2)
+	for _, v := range []float64{0.25, 0.5, 1.5} {
+		a.Observe(MetricDataSourceDuration, map[string]string{"pipeline": "p", "data_source": "users"}, v)
+	}
+	a.Observe("custom_seconds", map[string]string{"a": "b"}, math.Inf(1))
+
+	var b strings.Builder
+	if err := a.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus returned error: %v", err)
+	}
+
+	want := `# TYPE custom_total counter
+custom_total 2
+# HELP pipedream_data_source_calls_total Number of data source calls.
+# TYPE pipedream_data_source_calls_total counter
+pipedream_data_source_calls_total{data_source="users",pipeline="p"} 3
+pipedream_data_source_calls_total{data_source="users",pipeline="say \"hi\"\\now\n"} 1
+# TYPE custom_seconds summary
+custom_seconds{a="b",quantile="0.5"} +Inf
+custom_seconds{a="b",quantile="1"} +Inf
+custom_seconds_sum{a="b"} +Inf
+custom_seconds_count{a="b"} 1
+# HELP pipedream_data_source_duration_seconds Duration of data source calls in seconds.
+# TYPE pipedream_data_source_duration_seconds summary
+pipedream_data_source_duration_seconds{data_source="users",pipeline="p",quantile="0.5"} 0.5
+pipedream_data_source_duration_seconds{data_source="users",pipeline="p",quantile="1"} 1.5
+pipedream_data_source_duration_seconds_sum{data_source="users",pipeline="p"} 2.25
+pipedream_data_source_duration_seconds_count{data_source="users",pipeline="p"} 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("WritePrometheus wrote:\n%s\nwant:\n%s", got, want)
+	}
+}
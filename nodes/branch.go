@@ -1,6 +1,15 @@
 package nodes
 
-import "github.com/sidkurella/pipedream"
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sidkurella/pipedream"
+)
+
+// ErrTerminatedEarly is returned when a branch is taken that has no pipeline.
+// It wraps ErrPipelineExecutionStop, so the execution stops without failing.
+var ErrTerminatedEarly = fmt.Errorf("%w: branch has no pipeline", pipedream.ErrPipelineExecutionStop)
 
 // Executes one of two pipelines depending on the output of the condition
 type BranchNode struct {
@@ -21,3 +30,57 @@ type BranchNode struct {
 	// If true, the context will be cloned.
 	CloneContext bool
 }
+
+func (b BranchNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	res, err := ectx.EvaluateCondition(b.Condition, pctx)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate branch condition: %w", err)
+	}
+
+	next, field := b.FalsePipeline, "FalsePipeline"
+	skipped, skippedField := b.TruePipeline, "TruePipeline"
+	if res {
+		next, field = b.TruePipeline, "TruePipeline"
+		skipped, skippedField = b.FalsePipeline, "FalsePipeline"
+	}
+	if skipped != nil {
+		ectx.RecordSkipped(skippedField)
+	}
+	ectx.LogDecision("branch taken", "branch", field, "terminated", next == nil)
+	if next == nil {
+		return ErrTerminatedEarly
+	}
+
+	if b.CloneContext {
+		pctx = pctx.Clone()
+	}
+	return next.Execute(ectx.Sub(field), pctx)
+}
+
+// Validate implements the pipedream.Validator interface for BranchNode.
+// Both sub-pipelines are validated. Unless the context is cloned, keys written by every
+// sub-pipeline that can continue are visible to later nodes. A missing sub-pipeline or one that always
+// stops, e.g. by ending in a ReturnNode, cannot continue. If neither can, the branch always stops.
+func (b BranchNode) Validate(vctx pipedream.ValidationContext) {
+	vctx.Sub("Condition").ValidateCondition(b.Condition)
+
+	var branches []pipedream.ValidationContext
+	if b.TruePipeline != nil {
+		tv := vctx.Sub("TruePipeline").Clone()
+		tv.ValidatePipeline(*b.TruePipeline)
+		branches = append(branches, tv)
+	}
+	if b.FalsePipeline != nil {
+		fv := vctx.Sub("FalsePipeline").Clone()
+		fv.ValidatePipeline(*b.FalsePipeline)
+		branches = append(branches, fv)
+	}
+
+	if b.CloneContext {
+		if !slices.ContainsFunc(branches, func(bv pipedream.ValidationContext) bool { return !bv.Stops() }) {
+			vctx.MarkStops()
+		}
+		return
+	}
+	vctx.MergeBranches(branches...)
+}
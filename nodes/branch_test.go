@@ -0,0 +1,132 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sidkurella/pipedream"
+)
+
+func literal[T any](v T) pipedream.LiteralValue[T] {
+	return pipedream.LiteralValue[T]{Value: v}
+}
+
+func newExecutor() *pipedream.PipelineExecutor {
+	executor := pipedream.NewPipelineExecutor()
+	executor.RegisterDataSource(pipedream.NewDataSource("double", func(ctx context.Context, params int) (int, error) {
+		return params * 2, nil
+	}))
+	return executor
+}
+
+func TestBranchValidateGuardClause(t *testing.T) {
+	query := QueryNode{DataSourceName: "double", Params: literal(2), SaveToName: "x"}
+	readX := ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "x"}}
+
+	tests := []struct {
+		name   string
+		branch BranchNode
+	}{
+		{"false branch returns", BranchNode{
+			Condition:     &pipedream.ValueCondition{LHS: literal(1), RHS: literal(1), Operand: pipedream.ConditionEqual},
+			TruePipeline:  &pipedream.Pipeline{Nodes: []pipedream.Node{query}},
+			FalsePipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal(0)}}},
+		}},
+		{"false branch missing", BranchNode{
+			Condition:    &pipedream.ValueCondition{LHS: literal(1), RHS: literal(1), Operand: pipedream.ConditionEqual},
+			TruePipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{query}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := newExecutor()
+			p := pipedream.Pipeline{Nodes: []pipedream.Node{tt.branch, readX}}
+
+			if diags := executor.Validate(p); len(diags) != 0 {
+				t.Errorf("Validate = %v, want no diagnostics", diags)
+			}
+			v, err := executor.Execute(context.Background(), p)
+			if err != nil || v != 4 {
+				t.Errorf("Execute = %v, %v, want 4, nil", v, err)
+			}
+		})
+	}
+}
+
+func TestBranchValidateKeysFromBothBranches(t *testing.T) {
+	executor := newExecutor()
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		BranchNode{
+			Condition:     &pipedream.ValueCondition{LHS: literal(1), RHS: literal(1), Operand: pipedream.ConditionEqual},
+			TruePipeline:  &pipedream.Pipeline{Nodes: []pipedream.Node{QueryNode{DataSourceName: "double", Params: literal(2), SaveToName: "x"}}},
+			FalsePipeline: &pipedream.Pipeline{},
+		},
+		ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "x"}},
+	}}
+
+	diags := executor.Validate(p)
+	if len(diags) != 1 || diags[0].Path != "nodes[1].ValueBuilder.ContextKey" {
+		t.Errorf("Validate = %v, want a missing key at nodes[1].ValueBuilder.ContextKey", diags)
+	}
+}
+
+func TestBranchReport(t *testing.T) {
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		BranchNode{
+			Condition:     &pipedream.ValueCondition{LHS: literal(1), RHS: literal(1), Operand: pipedream.ConditionEqual},
+			TruePipeline:  &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal("yes")}}},
+			FalsePipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal("no")}}},
+		},
+		ReturnNode{ValueBuilder: literal("after")},
+	}}
+
+	v, report, err := newExecutor().ExecuteWithReport(context.Background(), p)
+	if err != nil || v != "yes" {
+		t.Fatalf("ExecuteWithReport = %v, %v, want yes, nil", v, err)
+	}
+	if report.StopReason != pipedream.StopReasonReturned {
+		t.Errorf("StopReason = %v, want returned", report.StopReason)
+	}
+	if len(report.SkippedBranches) != 1 || report.SkippedBranches[0] != "nodes[0].FalsePipeline" {
+		t.Errorf("SkippedBranches = %v, want [nodes[0].FalsePipeline]", report.SkippedBranches)
+	}
+	if n := report.Nodes[len(report.Nodes)-1]; n.Path != "nodes[0].TruePipeline.nodes[0]" || !n.Stopped {
+		t.Errorf("last node = %+v, want the stopped ReturnNode in the true branch", n)
+	}
+}
+
+func TestBranchValidateUnwrittenKey(t *testing.T) {
+	read := func(key string) ReturnNode {
+		return ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: key}}
+	}
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		BranchNode{
+			Condition: &pipedream.ValueCondition{LHS: &pipedream.DynamicValue{ContextKey: "cond"}, RHS: literal(1), Operand: pipedream.ConditionEqual},
+			TruePipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{
+				QueryNode{DataSourceName: "double", Params: literal(1), SaveToName: "t"},
+				QueryNode{DataSourceName: "double", Params: literal(1), SaveToName: "both"},
+			}},
+			FalsePipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{
+				QueryNode{DataSourceName: "double", Params: &pipedream.DynamicValue{ContextKey: "t"}, SaveToName: "both"},
+			}},
+		},
+		QueryNode{DataSourceName: "double", Params: &pipedream.DynamicValue{ContextKey: "both"}, SaveToName: "x"},
+		read("t"),
+	}}
+
+	diags := newExecutor().Validate(p)
+	want := []string{
+		"nodes[0].Condition.LHS.ContextKey",
+		"nodes[0].FalsePipeline.nodes[0].Params.ContextKey",
+		"nodes[2].ValueBuilder.ContextKey",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("Validate = %v, want %d diagnostics", diags, len(want))
+	}
+	for i, path := range want {
+		if diags[i].Path != path || !errors.Is(diags[i], pipedream.ErrValueNotFoundInContext) {
+			t.Errorf("diagnostic %d = %v, want ErrValueNotFoundInContext at %s", i, diags[i], path)
+		}
+	}
+}
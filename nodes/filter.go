@@ -1,8 +1,17 @@
 package nodes
 
-import "github.com/sidkurella/pipedream"
+import (
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/sidkurella/pipedream"
+)
 
 // Filters out data from a value and saves the filtered result to the pipeline context.
+// Slices and arrays are filtered into a slice, and maps into a map of the same type.
+// Streams and other sequences are filtered lazily into an iter.Seq2[any, error], so they are not buffered;
+// the condition is only evaluated as the result is consumed.
 type FilterNode struct {
 	// Condition to test for.
 	Condition pipedream.Condition
@@ -14,6 +23,160 @@ type FilterNode struct {
 	// Default is that elements passing the condition are kept.
 	Exclude bool
 
+	// Name the current element is bound to in the pipeline context while the condition is evaluated.
+	ElementName string
+
+	// Name the current index or map key is bound to in the pipeline context while the condition is evaluated.
+	// If empty, it is not bound.
+	KeyName string
+
 	// Name to save the filtered result into the pipeline context.
 	SaveToName string
 }
+
+func (f FilterNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	if f.Condition == nil {
+		return pipedream.ErrNilCondition
+	}
+	if f.Source == nil {
+		return fmt.Errorf("%w: no source to filter", pipedream.ErrNilValueBuilder)
+	}
+
+	source, err := f.Source.Build(pctx)
+	if err != nil {
+		return fmt.Errorf("failed to build filter source: %w", err)
+	}
+
+	// Elements are bound into a copy of the context so they do not leak into the rest of the pipeline.
+	epctx := pctx.Clone()
+	var in, out int
+	keep := func(key any, elem any) (bool, error) {
+		in++
+		epctx.SetValue(f.ElementName, elem)
+		if f.KeyName != "" {
+			epctx.SetValue(f.KeyName, key)
+		}
+		res, err := ectx.EvaluateCondition(f.Condition, epctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate filter condition: %w", err)
+		}
+		if res != f.Exclude {
+			out++
+			return true, nil
+		}
+		return false, nil
+	}
+
+	result, err := filterCollection(source, keep)
+	if err != nil {
+		return err
+	}
+	// Lazy results have not been filtered yet, so there is nothing to count.
+	if _, lazy := result.(iter.Seq2[any, error]); !lazy {
+		ectx.LogDecision("filter applied", "input", in, "output", out)
+		if in > 0 {
+			ectx.ObserveMetric(pipedream.MetricFilterSelectivity, float64(out)/float64(in))
+		}
+	}
+	pctx.SetValue(f.SaveToName, result)
+
+	return nil
+}
+
+// ResultKey implements the pipedream.ResultSaver interface for FilterNode.
+func (f FilterNode) ResultKey() string {
+	return f.SaveToName
+}
+
+// Validate implements the pipedream.Validator interface for FilterNode.
+func (f FilterNode) Validate(vctx pipedream.ValidationContext) {
+	vctx.Sub("Source").ValidateValue(f.Source)
+	if f.ElementName == "" {
+		vctx.Sub("ElementName").Report(pipedream.ErrNoContextKeyProvided)
+	}
+
+	sourceType := vctx.TypeOf(f.Source)
+	ev := vctx.Clone()
+	ev.DeclareKeyType(f.ElementName, pipedream.ElementType(sourceType))
+	if f.KeyName != "" {
+		ev.DeclareKey(f.KeyName)
+	}
+	ev.Sub("Condition").ValidateCondition(f.Condition)
+
+	vctx.DeclareKeyType(f.SaveToName, filteredType(sourceType))
+}
+
+func filterCollection(source any, keep func(key any, elem any) (bool, error)) (any, error) {
+	v := reflect.ValueOf(source)
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		out := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), 0, 0)
+		for i := range v.Len() {
+			ok, err := keep(i, v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = reflect.Append(out, v.Index(i))
+			}
+		}
+		return out.Interface(), nil
+	case reflect.Map:
+		out := reflect.MakeMap(v.Type())
+		iter := v.MapRange()
+		for iter.Next() {
+			ok, err := keep(iter.Key().Interface(), iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		return out.Interface(), nil
+	case reflect.Func:
+		return filterLazy(source, keep), nil
+	default:
+		// Let RangeElements produce the error.
+		return nil, pipedream.RangeElements(source, func(key any, elem any) (bool, error) { return false, nil })
+	}
+}
+
+// filterLazy filters a sequence as it is consumed, without buffering it.
+func filterLazy(source any, keep func(key any, elem any) (bool, error)) iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		err := pipedream.RangeElements(source, func(key any, elem any) (bool, error) {
+			ok, err := keep(key, elem)
+			if err != nil || !ok {
+				return err == nil, err
+			}
+			return yield(elem, nil), nil
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// filteredType returns the static type of the result of filtering a collection of type t.
+func filteredType(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return filteredType(t.Elem())
+	case reflect.Array, reflect.Slice:
+		return reflect.SliceOf(t.Elem())
+	case reflect.Map:
+		return t
+	case reflect.Func:
+		return reflect.TypeFor[iter.Seq2[any, error]]()
+	default:
+		return nil
+	}
+}
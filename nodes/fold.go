@@ -1,20 +1,144 @@
 package nodes
 
-import "github.com/sidkurella/pipedream"
+import (
+	"fmt"
+
+	"github.com/sidkurella/pipedream"
+)
 
 // FoldNode aggregates elements in a list of values according to the specified aggregation method.
+// Streams and other sequences are consumed one element at a time without buffering, unless RightToLeft is set.
+// Maps are folded in an unspecified order.
 type FoldNode struct {
 	// Value to filter.
 	Source pipedream.ValueBuilder
 
 	// RightToLeft flips the direction of the fold to start with the last element of source, and work backwards.
+	// Sequences have to be buffered to fold them right to left.
 	RightToLeft bool
 
 	// StartValue sets the starting value of the accumulator.
+	// If nil, the accumulator starts as nil.
 	StartValue pipedream.ValueBuilder
 
-	// TODO: How to specify the actual aggregation method itself?
+	// Aggregate builds the next value of the accumulator for each element.
+	// It is evaluated with the accumulator and the current element bound into the pipeline context
+	// under AccumulatorName and ElementName.
+	Aggregate pipedream.ValueBuilder
+
+	// Name the accumulator is bound to in the pipeline context while Aggregate is evaluated.
+	AccumulatorName string
+
+	// Name the current element is bound to in the pipeline context while Aggregate is evaluated.
+	ElementName string
+
+	// Name the current index or map key is bound to in the pipeline context while Aggregate is evaluated.
+	// If empty, it is not bound.
+	KeyName string
 
 	// Name to save the aggregated result into the pipeline context.
 	SaveToName string
 }
+
+func (f FoldNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	if f.Source == nil || f.Aggregate == nil {
+		return fmt.Errorf("%w: fold needs a source and an aggregate", pipedream.ErrNilValueBuilder)
+	}
+
+	source, err := f.Source.Build(pctx)
+	if err != nil {
+		return fmt.Errorf("failed to build fold source: %w", err)
+	}
+
+	var acc any
+	if f.StartValue != nil {
+		acc, err = f.StartValue.Build(pctx)
+		if err != nil {
+			return fmt.Errorf("failed to build fold start value: %w", err)
+		}
+	}
+
+	// Elements are bound into a copy of the context so they do not leak into the rest of the pipeline.
+	fpctx := pctx.Clone()
+	var count int
+	step := func(key any, elem any) (bool, error) {
+		count++
+		fpctx.SetValue(f.AccumulatorName, acc)
+		fpctx.SetValue(f.ElementName, elem)
+		if f.KeyName != "" {
+			fpctx.SetValue(f.KeyName, key)
+		}
+
+		var err error
+		acc, err = f.Aggregate.Build(fpctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to build fold aggregate: %w", err)
+		}
+		return true, nil
+	}
+
+	if f.RightToLeft {
+		err = foldRight(source, step)
+	} else {
+		err = pipedream.RangeElements(source, step)
+	}
+	if err != nil {
+		return err
+	}
+	ectx.ObserveMetric(pipedream.MetricFoldElements, float64(count))
+	pctx.SetValue(f.SaveToName, acc)
+
+	return nil
+}
+
+// ResultKey implements the pipedream.ResultSaver interface for FoldNode.
+func (f FoldNode) ResultKey() string {
+	return f.SaveToName
+}
+
+// Validate implements the pipedream.Validator interface for FoldNode.
+func (f FoldNode) Validate(vctx pipedream.ValidationContext) {
+	vctx.Sub("Source").ValidateValue(f.Source)
+	if f.StartValue != nil {
+		vctx.Sub("StartValue").ValidateValue(f.StartValue)
+	}
+	if f.AccumulatorName == "" {
+		vctx.Sub("AccumulatorName").Report(pipedream.ErrNoContextKeyProvided)
+	}
+	if f.ElementName == "" {
+		vctx.Sub("ElementName").Report(pipedream.ErrNoContextKeyProvided)
+	}
+
+	av := vctx.Clone()
+	av.DeclareKey(f.AccumulatorName)
+	av.DeclareKeyType(f.ElementName, pipedream.ElementType(vctx.TypeOf(f.Source)))
+	if f.KeyName != "" {
+		av.DeclareKey(f.KeyName)
+	}
+	av.Sub("Aggregate").ValidateValue(f.Aggregate)
+
+	vctx.DeclareKey(f.SaveToName)
+}
+
+func foldRight(source any, step func(key any, elem any) (bool, error)) error {
+	type entry struct {
+		key  any
+		elem any
+	}
+
+	var entries []entry
+	err := pipedream.RangeElements(source, func(key any, elem any) (bool, error) {
+		entries = append(entries, entry{key: key, elem: elem})
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if _, err := step(entries[i].key, entries[i].elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/sidkurella/pipedream"
+)
+
+func TestDecisionLogs(t *testing.T) {
+	var buf bytes.Buffer
+	executor := newExecutor()
+	executor.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)), pipedream.LogConfig{DecisionLevel: slog.LevelInfo})
+
+	p := pipedream.Pipeline{Name: "decisions", Nodes: []pipedream.Node{
+		FilterNode{Source: literal([]int{1, 2, 3, 4, 5}), Condition: evenCondition{}, ElementName: "n", SaveToName: "even"},
+		BranchNode{
+			Condition:    &pipedream.ValueCondition{LHS: literal(1), RHS: literal(2), Operand: pipedream.ConditionEqual},
+			TruePipeline: &pipedream.Pipeline{},
+		},
+	}}
+	if _, err := executor.Execute(context.Background(), p); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	var records []map[string]any
+	var conditions int
+	for dec := json.NewDecoder(&buf); dec.More(); {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("decoding log record: %v", err)
+		}
+		if r["msg"] == "condition evaluated" {
+			conditions++
+			continue
+		}
+		records = append(records, r)
+	}
+
+	// Only decisions are logged at the handler's default Info level: a condition for each filtered element
+	// and the branch, and the filter and branch results.
+	if conditions != 6 {
+		t.Errorf("got %d condition records, want 6", conditions)
+	}
+	want := []map[string]any{
+		{"msg": "filter applied", "path": "nodes[0]", "input": 5.0, "output": 2.0},
+		{"msg": "branch taken", "path": "nodes[1]", "branch": "FalsePipeline", "terminated": true},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got records %v, want %d", records, len(want))
+	}
+	for i, w := range want {
+		r := records[i]
+		if r["level"] != "INFO" || r["pipeline"] != "decisions" || r["execution_id"] == "" {
+			t.Errorf("record %d = %v, want INFO with pipeline and execution ID", i, r)
+		}
+		for k, v := range w {
+			if r[k] != v {
+				t.Errorf("record %d has %s = %v, want %v", i, k, r[k], v)
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/sidkurella/pipedream"
+)
+
+var ErrInvalidTransform = fmt.Errorf("map node needs exactly one of Transform or Pipeline")
+
+// Transforms every element of a value and saves the results to the pipeline context.
+// Maps are transformed into a map[K]any with the same keys, and anything else RangeElements supports,
+// such as slices and arrays, into a []any in the same order.
+type MapNode struct {
+	// Value to transform.
+	Source pipedream.ValueBuilder
+
+	// Transform builds the result for each element. Set either Transform or Pipeline.
+	Transform pipedream.ValueBuilder
+
+	// Pipeline runs for each element. The result is the value it returns with ReturnNode if it does,
+	// and otherwise the value it saves to ResultName. Set either Transform or Pipeline.
+	// Returning or stopping with pipedream.ErrPipelineExecutionStop ends the pipeline for that element only.
+	Pipeline *pipedream.Pipeline
+
+	// Name the result is read from after Pipeline runs. Required if Pipeline is set and does not always return.
+	ResultName string
+
+	// Name the current element is bound to in the pipeline context while it is transformed.
+	ElementName string
+
+	// Name the current index or map key is bound to in the pipeline context while the element is transformed.
+	// If empty, it is not bound.
+	KeyName string
+
+	// Name to save the transformed result into the pipeline context.
+	SaveToName string
+
+	// Parallelism is the maximum number of elements transformed at once. Values below 2 transform elements
+	// one at a time. Results are in the same order either way. Once an element fails,
+	// no more are started and the context of those still running is canceled.
+	Parallelism int
+}
+
+// mapEntry is an element being transformed, and its result once it has been.
+type mapEntry struct {
+	key    any
+	result any
+}
+
+func (m MapNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	if (m.Transform == nil) == (m.Pipeline == nil) {
+		return ErrInvalidTransform
+	}
+	if m.Source == nil {
+		return fmt.Errorf("%w: no source to map", pipedream.ErrNilValueBuilder)
+	}
+
+	source, err := m.Source.Build(pctx)
+	if err != nil {
+		return fmt.Errorf("failed to build map source: %w", err)
+	}
+
+	entries, err := m.transformAll(ectx, pctx, source)
+	if err != nil {
+		return err
+	}
+	pctx.SetValue(m.SaveToName, mappedResult(source, entries))
+
+	return nil
+}
+
+// transformAll transforms the elements of the source, up to Parallelism at a time. The first error to occur is returned.
+// Elements are only read from the source as they are started.
+func (m MapNode) transformAll(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext, source any) ([]*mapEntry, error) {
+	var entries []*mapEntry
+
+	if m.Parallelism < 2 {
+		err := pipedream.RangeElements(source, func(key any, elem any) (bool, error) {
+			e := &mapEntry{key: key}
+			var err error
+			e.result, err = m.transform(ectx, m.bind(pctx, key, elem), key)
+			if err != nil {
+				return false, err
+			}
+			entries = append(entries, e)
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+
+	ectx, cancel := ectx.WithCancel()
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, m.Parallelism)
+	rangeErr := pipedream.RangeElements(source, func(key any, elem any) (bool, error) {
+		sem <- struct{}{}
+		if ectx.Context().Err() != nil {
+			// An element failed, or the execution was canceled. Do not start any more.
+			<-sem
+			return false, nil
+		}
+
+		// pctx is not written until every element is done, so it can be cloned while others run.
+		e := &mapEntry{key: key}
+		epctx := m.bind(pctx, key, elem)
+		entries = append(entries, e)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			v, err := m.transform(ectx, epctx, key)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			e.result = v
+		}()
+		return true, nil
+	})
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if rangeErr != nil {
+		return nil, rangeErr
+	}
+	if err := ectx.Context().Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// bind returns a copy of the context with the element bound, so it does not leak into the rest of the pipeline
+// or into other elements.
+func (m MapNode) bind(pctx pipedream.PipelineContext, key any, elem any) pipedream.PipelineContext {
+	epctx := pctx.Clone()
+	epctx.SetValue(m.ElementName, elem)
+	if m.KeyName != "" {
+		epctx.SetValue(m.KeyName, key)
+	}
+	return epctx
+}
+
+func (m MapNode) transform(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext, key any) (any, error) {
+	if m.Transform != nil {
+		v, err := m.Transform.Build(pctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform element %v: %w", key, err)
+		}
+		return v, nil
+	}
+
+	// Each element gets its own path, so errors, spans and reports can tell the elements apart,
+	// and its own return value, so that returning ends only that element's pipeline.
+	ectx = ectx.Sub(fmt.Sprintf("Pipeline[%v]", key)).WithReturnScope()
+	err := m.Pipeline.Execute(ectx, pctx)
+	if err != nil && !errors.Is(err, pipedream.ErrPipelineExecutionStop) {
+		return nil, fmt.Errorf("failed to transform element %v: %w", key, err)
+	}
+	if ectx.Returned() {
+		return ectx.ReturnValue(), nil
+	}
+	v, _ := pctx.GetValue(m.ResultName)
+	return v, nil
+}
+
+// ResultKey implements the pipedream.ResultSaver interface for MapNode.
+func (m MapNode) ResultKey() string {
+	return m.SaveToName
+}
+
+// Validate implements the pipedream.Validator interface for MapNode.
+func (m MapNode) Validate(vctx pipedream.ValidationContext) {
+	vctx.Sub("Source").ValidateValue(m.Source)
+	if m.ElementName == "" {
+		vctx.Sub("ElementName").Report(pipedream.ErrNoContextKeyProvided)
+	}
+	if (m.Transform == nil) == (m.Pipeline == nil) {
+		vctx.Report(ErrInvalidTransform)
+	}
+
+	sourceType := vctx.TypeOf(m.Source)
+	ev := vctx.Clone()
+	ev.DeclareKeyType(m.ElementName, pipedream.ElementType(sourceType))
+	if m.KeyName != "" {
+		ev.DeclareKey(m.KeyName)
+	}
+	if m.Transform != nil {
+		ev.Sub("Transform").ValidateValue(m.Transform)
+	}
+	if m.Pipeline != nil {
+		ev.Sub("Pipeline").ValidatePipeline(*m.Pipeline)
+		switch {
+		case ev.Stops():
+			// Every element returns its result, or stops without one.
+		case m.ResultName == "":
+			vctx.Sub("ResultName").Report(pipedream.ErrNoContextKeyProvided)
+		case !ev.HasKey(m.ResultName):
+			vctx.Sub("ResultName").Report(fmt.Errorf("%w: pipeline does not write %q", pipedream.ErrValueNotFoundInContext, m.ResultName))
+		}
+	}
+
+	vctx.DeclareKeyType(m.SaveToName, mappedType(sourceType))
+}
+
+// mappedResult assembles the results into a map with the source's keys if the source is a map,
+// and into a slice otherwise.
+func mappedResult(source any, entries []*mapEntry) any {
+	v := reflect.ValueOf(source)
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Map {
+		results := make([]any, len(entries))
+		for i, e := range entries {
+			results[i] = e.result
+		}
+		return results
+	}
+
+	keyType := v.Type().Key()
+	out := reflect.MakeMapWithSize(reflect.MapOf(keyType, reflect.TypeFor[any]()), len(entries))
+	for _, e := range entries {
+		key := reflect.ValueOf(e.key)
+		if !key.IsValid() {
+			key = reflect.Zero(keyType)
+		}
+		out.SetMapIndex(key, reflect.ValueOf(&e.result).Elem())
+	}
+	return out.Interface()
+}
+
+// mappedType returns the static type of the result of mapping a collection of type t.
+func mappedType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Map {
+		return reflect.MapOf(t.Key(), reflect.TypeFor[any]())
+	}
+	return reflect.TypeFor[[]any]()
+}
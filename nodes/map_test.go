@@ -0,0 +1,210 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sidkurella/pipedream"
+)
+
+// nodeFunc adapts a function to the pipedream.Node interface.
+type nodeFunc func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error
+
+func (f nodeFunc) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	return f(ectx, pctx)
+}
+
+func runMap(t *testing.T, m MapNode) (any, error) {
+	t.Helper()
+	v, err := newExecutor().Execute(context.Background(), pipedream.Pipeline{Nodes: []pipedream.Node{
+		m,
+		ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "out"}},
+	}})
+	return v, err
+}
+
+func TestMapTransform(t *testing.T) {
+	key := &pipedream.DynamicValue{ContextKey: "k"}
+
+	tests := []struct {
+		name   string
+		source any
+		want   any
+	}{
+		{"slice", []string{"a", "b"}, []any{0, 1}},
+		{"array", [2]string{"a", "b"}, []any{0, 1}},
+		{"map", map[string]int{"x": 1, "y": 2}, map[string]any{"x": "x", "y": "y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := runMap(t, MapNode{
+				Source:      pipedream.LiteralValue[any]{Value: tt.source},
+				Transform:   key,
+				ElementName: "e",
+				KeyName:     "k",
+				SaveToName:  "out",
+			})
+			if err != nil || !reflect.DeepEqual(v, tt.want) {
+				t.Errorf("got %v, %v, want %v, nil", v, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapPipelineParallel(t *testing.T) {
+	recorder := pipedream.NewTraceRecorder()
+	executor := newExecutor()
+	executor.SetTracer(recorder)
+
+	double := QueryNode{DataSourceName: "double", Params: &pipedream.DynamicValue{ContextKey: "e"}, SaveToName: "r"}
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		MapNode{
+			Source:      literal([]int{1, 2, 3, 4, 5}),
+			Pipeline:    &pipedream.Pipeline{Nodes: []pipedream.Node{double}},
+			ResultName:  "r",
+			ElementName: "e",
+			SaveToName:  "out",
+			Parallelism: 3,
+		},
+		ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "out"}},
+	}}
+
+	if diags := executor.Validate(p); len(diags) != 0 {
+		t.Errorf("Validate = %v, want no diagnostics", diags)
+	}
+	v, err := executor.Execute(context.Background(), p)
+	if want := []any{2, 4, 6, 8, 10}; err != nil || !reflect.DeepEqual(v, want) {
+		t.Errorf("got %v, %v, want %v, nil", v, err, want)
+	}
+
+	var paths []string
+	for _, s := range recorder.Spans() {
+		if s.Info.Kind == pipedream.SpanNode && s.Info.Name == "nodes.QueryNode" {
+			paths = append(paths, s.Info.Path)
+		}
+	}
+	slices.Sort(paths)
+	want := []string{
+		"nodes[0].Pipeline[0].nodes[0]",
+		"nodes[0].Pipeline[1].nodes[0]",
+		"nodes[0].Pipeline[2].nodes[0]",
+		"nodes[0].Pipeline[3].nodes[0]",
+		"nodes[0].Pipeline[4].nodes[0]",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("element paths = %v, want %v", paths, want)
+	}
+}
+
+func TestMapParallelCancelsOnFailure(t *testing.T) {
+	boom := errors.New("boom")
+	element := nodeFunc(func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+		if e, _ := pctx.GetValue("e"); e == 0 {
+			return boom
+		}
+		select {
+		case <-ectx.Context().Done():
+			return ectx.Context().Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+
+	start := time.Now()
+	_, err := runMap(t, MapNode{
+		Source:      literal([]int{1, 2, 0, 3, 4, 5}),
+		Pipeline:    &pipedream.Pipeline{Nodes: []pipedream.Node{element}},
+		ElementName: "e",
+		ResultName:  "r",
+		SaveToName:  "out",
+		Parallelism: 3,
+	})
+
+	var pe *pipedream.PipelineError
+	if !errors.Is(err, boom) || !errors.As(err, &pe) || pe.Path != "nodes[0].Pipeline[2].nodes[0]" {
+		t.Errorf("got %v, want boom from nodes[0].Pipeline[2].nodes[0]", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v, running elements were not canceled", elapsed)
+	}
+}
+
+func TestMapPipelineReturnEndsElementOnly(t *testing.T) {
+	// Odd elements return their result; even ones save it to ResultName.
+	element := &pipedream.Pipeline{Nodes: []pipedream.Node{
+		BranchNode{
+			Condition:     &pipedream.ValueCondition{LHS: &pipedream.DynamicValue{ContextKey: "odd"}, RHS: literal(true), Operand: pipedream.ConditionEqual},
+			TruePipeline:  &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal("odd")}}},
+			FalsePipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{QueryNode{DataSourceName: "double", Params: &pipedream.DynamicValue{ContextKey: "e"}, SaveToName: "r"}}},
+		},
+	}}
+
+	for _, parallelism := range []int{0, 3} {
+		p := pipedream.Pipeline{Nodes: []pipedream.Node{
+			MapNode{
+				Source:      literal(map[int]bool{1: true, 2: false, 3: true}),
+				Pipeline:    element,
+				ElementName: "odd",
+				KeyName:     "e",
+				ResultName:  "r",
+				SaveToName:  "out",
+				Parallelism: parallelism,
+			},
+			nodeFunc(func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+				pctx.SetValue("after", true)
+				return nil
+			}),
+		}}
+
+		if diags := newExecutor().Validate(p); len(diags) != 0 {
+			t.Errorf("parallelism %d: Validate = %v, want no diagnostics", parallelism, diags)
+		}
+		v, report, err := newExecutor().ExecuteWithReport(context.Background(), p)
+		if err != nil || v != nil {
+			t.Errorf("parallelism %d: got %v, %v, want nil, nil", parallelism, v, err)
+		}
+		if report.StopReason != pipedream.StopReasonCompleted || report.Context["after"] != true {
+			t.Errorf("parallelism %d: stopped with %v, want the pipeline to complete", parallelism, report.StopReason)
+		}
+		if want := map[int]any{1: "odd", 2: 4, 3: "odd"}; !reflect.DeepEqual(report.Context["out"], want) {
+			t.Errorf("parallelism %d: out = %v, want %v", parallelism, report.Context["out"], want)
+		}
+	}
+}
+
+func TestMapReadsSourceLazily(t *testing.T) {
+	boom := errors.New("boom")
+	seq := func(yield func(int, int) bool) {
+		for i := range 10 {
+			if i > 1 {
+				t.Errorf("element %d read after element 1 failed", i)
+				return
+			}
+			if !yield(i, i) {
+				return
+			}
+		}
+	}
+	failOnOne := nodeFunc(func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+		if e, _ := pctx.GetValue("e"); e == 1 {
+			return boom
+		}
+		return nil
+	})
+
+	_, err := runMap(t, MapNode{
+		Source:      pipedream.LiteralValue[any]{Value: iter.Seq2[int, int](seq)},
+		Pipeline:    &pipedream.Pipeline{Nodes: []pipedream.Node{failOnOne}},
+		ElementName: "e",
+		ResultName:  "r",
+		SaveToName:  "out",
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("got %v, want boom", err)
+	}
+}
@@ -2,11 +2,22 @@ package nodes
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/sidkurella/pipedream"
 )
 
-var ErrDataSourceNotFound = fmt.Errorf("data source not found")
+var ErrDataSourceNotFound = pipedream.ErrDataSourceNotFound
+var ErrInvalidLimit = fmt.Errorf("stream take limit must be positive")
+
+// StreamMode controls how a QueryNode saves the response of a stream data source.
+type StreamMode int
+
+const (
+	StreamLazy    StreamMode = iota // Save the lazy iter.Seq2 itself, for downstream nodes to consume.
+	StreamCollect                   // Collect every element into a slice.
+	StreamTake                      // Collect up to Limit elements into a slice.
+)
 
 // Queries a data source for data which is then saved to the pipeline context.
 type QueryNode struct {
@@ -18,9 +29,19 @@ type QueryNode struct {
 
 	// Name to save this into the pipeline context.
 	SaveToName string
+
+	// StreamMode controls how responses from stream data sources are saved. Ignored for other data sources.
+	StreamMode StreamMode
+
+	// Limit is the number of elements to take when StreamMode is StreamTake. It must be positive.
+	Limit int
 }
 
-func (q QueryNode) Execute(pctx pipedream.PipelineContext) error {
+func (q QueryNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	if q.Params == nil {
+		return fmt.Errorf("%w: no params to query %s", pipedream.ErrNilValueBuilder, q.DataSourceName)
+	}
+
 	// Build input parameters.
 	params, err := q.Params.Build(pctx)
 	if err != nil {
@@ -28,21 +49,63 @@ func (q QueryNode) Execute(pctx pipedream.PipelineContext) error {
 	}
 
 	// Get the data source with the defined name.
-	// Assuming PipelineContext has a method to get data sources.
-	dataSource, found := pctx.GetDataSource(q.DataSourceName)
-	if !found {
-		return fmt.Errorf("%w: %s", ErrDataSourceNotFound, q.DataSourceName)
+	dataSource, err := ectx.GetDataSource(q.DataSourceName)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, q.DataSourceName)
 	}
 
 	// Query the data source.
-	// Assuming the DataSource interface has a Query method.
-	result, err := dataSource.Query(params)
+	result, err := dataSource.Get(ectx.Context(), params)
 	if err != nil {
 		return fmt.Errorf("failed to query data source %s: %w", q.DataSourceName, err)
 	}
 
+	if dataSource.IsStream() && q.StreamMode != StreamLazy {
+		limit := -1
+		if q.StreamMode == StreamTake {
+			if q.Limit <= 0 {
+				return fmt.Errorf("%w: got %d", ErrInvalidLimit, q.Limit)
+			}
+			limit = q.Limit
+		}
+		result, err = dataSource.Collect(result, limit)
+		if err != nil {
+			return fmt.Errorf("failed to read stream from data source %s: %w", q.DataSourceName, err)
+		}
+	}
+
 	// Save the result to the context.
 	pctx.SetValue(q.SaveToName, result)
 
 	return nil
 }
+
+// ResultKey implements the pipedream.ResultSaver interface for QueryNode.
+func (q QueryNode) ResultKey() string {
+	return q.SaveToName
+}
+
+// Validate implements the pipedream.Validator interface for QueryNode.
+func (q QueryNode) Validate(vctx pipedream.ValidationContext) {
+	vctx.Sub("Params").ValidateValue(q.Params)
+	if q.StreamMode == StreamTake && q.Limit <= 0 {
+		vctx.Sub("Limit").Report(fmt.Errorf("%w: got %d", ErrInvalidLimit, q.Limit))
+	}
+
+	dataSource, err := vctx.GetDataSource(q.DataSourceName)
+	if err != nil {
+		vctx.Sub("DataSourceName").Report(fmt.Errorf("%w: %s", err, q.DataSourceName))
+		vctx.DeclareKey(q.SaveToName)
+		return
+	}
+
+	if t := vctx.TypeOf(q.Params); t != nil && !t.ConvertibleTo(dataSource.ParamType()) {
+		vctx.Sub("Params").Report(fmt.Errorf("%w: %s expects %s, got %s",
+			pipedream.ErrParamTypeDoesNotMatch, q.DataSourceName, dataSource.ParamType(), t))
+	}
+	if dataSource.IsStream() && q.StreamMode != StreamLazy {
+		vctx.DeclareKeyType(q.SaveToName, reflect.SliceOf(dataSource.StreamElementType()))
+		return
+	}
+	vctx.DeclareKeyType(q.SaveToName, dataSource.ResponseType())
+}
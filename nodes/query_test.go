@@ -0,0 +1,231 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/sidkurella/pipedream"
+)
+
+// countingStream registers a stream of 0 up to the params, which logs each element read
+// and fails the test if more than max are read.
+func countingStream(t *testing.T, executor *pipedream.PipelineExecutor, max int, log *[]string) {
+	executor.RegisterDataSource(pipedream.NewStreamDataSource("numbers", func(ctx context.Context, params int) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {
+			for i := range params {
+				if i >= max {
+					t.Errorf("element %d read, want at most %d", i, max)
+					return
+				}
+				*log = append(*log, fmt.Sprint("read ", i))
+				if !yield(i, nil) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+func TestQueryStreamModes(t *testing.T) {
+	var log []string
+	executor := newExecutor()
+	countingStream(t, executor, 3, &log)
+
+	take := QueryNode{DataSourceName: "numbers", Params: literal(100), SaveToName: "out", StreamMode: StreamTake, Limit: 3}
+	v, err := executor.Execute(context.Background(), pipedream.Pipeline{Nodes: []pipedream.Node{
+		take,
+		ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "out"}},
+	}})
+	if err != nil || !reflect.DeepEqual(v, []int{0, 1, 2}) {
+		t.Errorf("StreamTake = %v, %v, want [0 1 2], nil", v, err)
+	}
+
+	collect := QueryNode{DataSourceName: "numbers", Params: literal(2), SaveToName: "out", StreamMode: StreamCollect}
+	v, err = executor.Execute(context.Background(), pipedream.Pipeline{Nodes: []pipedream.Node{
+		collect,
+		ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "out"}},
+	}})
+	if err != nil || !reflect.DeepEqual(v, []int{0, 1}) {
+		t.Errorf("StreamCollect = %v, %v, want [0 1], nil", v, err)
+	}
+}
+
+func TestQueryStreamTakeNeedsLimit(t *testing.T) {
+	var log []string
+	executor := newExecutor()
+	countingStream(t, executor, 0, &log)
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		QueryNode{DataSourceName: "numbers", Params: literal(10), SaveToName: "out", StreamMode: StreamTake},
+	}}
+
+	diags := executor.Validate(p)
+	if len(diags) != 1 || diags[0].Path != "nodes[0].Limit" || !errors.Is(diags[0], ErrInvalidLimit) {
+		t.Errorf("Validate = %v, want ErrInvalidLimit at nodes[0].Limit", diags)
+	}
+	if _, err := executor.Execute(context.Background(), p); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("got %v, want ErrInvalidLimit", err)
+	}
+}
+
+// evenCondition is true if the element bound to "n" is even.
+type evenCondition struct{}
+
+func (evenCondition) Evaluate(pctx pipedream.PipelineContext) (bool, error) {
+	n, _ := pctx.GetValue("n")
+	return n.(int)%2 == 0, nil
+}
+
+// sumValue adds the element bound to "n" to the accumulator bound to "acc", logging each step.
+type sumValue struct {
+	log *[]string
+}
+
+func (s sumValue) Build(pctx pipedream.PipelineContext) (any, error) {
+	acc, _ := pctx.GetValue("acc")
+	n, _ := pctx.GetValue("n")
+	*s.log = append(*s.log, fmt.Sprint("fold ", n))
+	return acc.(int) + n.(int), nil
+}
+
+func TestLazyStreamFilterFold(t *testing.T) {
+	var log []string
+	executor := newExecutor()
+	countingStream(t, executor, 5, &log)
+
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		QueryNode{DataSourceName: "numbers", Params: literal(5), SaveToName: "stream"},
+		FilterNode{Source: &pipedream.DynamicValue{ContextKey: "stream"}, Condition: evenCondition{}, ElementName: "n", SaveToName: "even"},
+		nodeFunc(func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+			if len(log) != 0 {
+				t.Errorf("stream read %v before it was consumed", log)
+			}
+			return nil
+		}),
+		FoldNode{
+			Source:          &pipedream.DynamicValue{ContextKey: "even"},
+			StartValue:      literal(0),
+			Aggregate:       sumValue{log: &log},
+			AccumulatorName: "acc",
+			ElementName:     "n",
+			SaveToName:      "sum",
+		},
+		ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "sum"}},
+	}}
+
+	v, err := executor.Execute(context.Background(), p)
+	if err != nil || v != 6 {
+		t.Errorf("got %v, %v, want 6, nil", v, err)
+	}
+	// Each element is folded as soon as it is read, rather than after the whole stream was buffered.
+	want := []string{"read 0", "fold 0", "read 1", "read 2", "fold 2", "read 3", "read 4", "fold 4"}
+	if !slices.Equal(log, want) {
+		t.Errorf("log = %v, want %v", log, want)
+	}
+}
+
+func TestQueryValidateWithAdditionalDataSources(t *testing.T) {
+	executor := newExecutor()
+	extra := pipedream.NewDataSource("extra", func(ctx context.Context, params int) (string, error) {
+		return "", nil
+	})
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		QueryNode{DataSourceName: "extra", Params: literal(1), SaveToName: "x"},
+	}}
+
+	if diags := executor.Validate(p); len(diags) != 1 || !errors.Is(diags[0], pipedream.ErrDataSourceNotFound) {
+		t.Errorf("Validate = %v, want ErrDataSourceNotFound", diags)
+	}
+	if diags := executor.Validate(p, pipedream.WithAdditionalDataSources(extra)); len(diags) != 0 {
+		t.Errorf("Validate with the data source = %v, want no diagnostics", diags)
+	}
+}
+
+func TestQueryValidateResponseType(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+	executor := newExecutor()
+	executor.RegisterDataSource(pipedream.NewDataSource("users", func(ctx context.Context, params int) ([]user, error) {
+		return nil, nil
+	}))
+
+	query := QueryNode{DataSourceName: "users", Params: literal(1), SaveToName: "users"}
+	branch := func(lhs pipedream.ValueBuilder, rhs pipedream.ValueBuilder) BranchNode {
+		return BranchNode{
+			Condition:    &pipedream.ValueCondition{LHS: lhs, RHS: rhs, Operand: pipedream.ConditionEqual},
+			TruePipeline: &pipedream.Pipeline{},
+		}
+	}
+	first := &pipedream.DynamicValue{ContextKey: "users", Getter: pipedream.DefaultValueGetter{}, Key: 0}
+
+	tests := []struct {
+		name string
+		node pipedream.Node
+		want error
+	}{
+		{"int response against string", pipedream.Pipeline{Nodes: []pipedream.Node{
+			QueryNode{DataSourceName: "double", Params: literal(1), SaveToName: "x"},
+			branch(&pipedream.DynamicValue{ContextKey: "x"}, literal("a")),
+		}}, pipedream.ErrIncompatibleTypes},
+		{"element against int", branch(first, literal(1)), pipedream.ErrIncompatibleTypes},
+		{"fold element field", FoldNode{
+			Source:          &pipedream.DynamicValue{ContextKey: "users"},
+			Aggregate:       &pipedream.DynamicValue{ContextKey: "u", Getter: pipedream.DefaultValueGetter{}, Key: "Age"},
+			AccumulatorName: "acc",
+			ElementName:     "u",
+			SaveToName:      "age",
+		}, nil},
+		{"filtered element field against int", pipedream.Pipeline{Nodes: []pipedream.Node{
+			FilterNode{
+				Source: &pipedream.DynamicValue{ContextKey: "users"},
+				Condition: &pipedream.ValueCondition{
+					LHS:     &pipedream.DynamicValue{ContextKey: "u", Getter: pipedream.DefaultValueGetter{}, Key: "Name"},
+					RHS:     literal(30),
+					Operand: pipedream.ConditionLessThan,
+				},
+				ElementName: "u",
+				SaveToName:  "young",
+			},
+		}}, pipedream.ErrIncompatibleTypes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := executor.Validate(pipedream.Pipeline{Nodes: []pipedream.Node{query, tt.node}})
+			if tt.want == nil {
+				if len(diags) != 0 {
+					t.Errorf("Validate = %v, want no diagnostics", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || !errors.Is(diags[0], tt.want) {
+				t.Errorf("Validate = %v, want %v", diags, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryValidateUnregisteredDataSource(t *testing.T) {
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		QueryNode{DataSourceName: "missing", Params: literal(1), SaveToName: "a"},
+		QueryNode{DataSourceName: "double", Params: literal(1), SaveToName: "b"},
+		QueryNode{DataSourceName: "other", Params: &pipedream.DynamicValue{ContextKey: "a"}, SaveToName: "c"},
+	}}
+
+	// Keys saved by queries to unregistered data sources are still declared, so only the data sources are reported.
+	diags := newExecutor().Validate(p)
+	want := []string{"nodes[0].DataSourceName", "nodes[2].DataSourceName"}
+	if len(diags) != len(want) {
+		t.Fatalf("Validate = %v, want %d diagnostics", diags, len(want))
+	}
+	for i, path := range want {
+		if diags[i].Path != path || !errors.Is(diags[i], pipedream.ErrDataSourceNotFound) {
+			t.Errorf("diagnostic %d = %v, want ErrDataSourceNotFound at %s", i, diags[i], path)
+		}
+	}
+}
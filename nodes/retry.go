@@ -0,0 +1,120 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/sidkurella/pipedream"
+)
+
+// ErrPipelineRetriesExhausted is returned when every attempt of a RetryNode failed.
+var ErrPipelineRetriesExhausted = fmt.Errorf("pipeline retries exhausted")
+
+// Executes a pipeline, retrying it as a whole if it fails.
+// Each attempt runs on a copy of the context, and only the writes of the attempt that succeeds are kept.
+type RetryNode struct {
+	// Pipeline to execute.
+	Pipeline *pipedream.Pipeline
+
+	// MaxAttempts is the number of attempts in total. Values below 1 mean a single attempt.
+	MaxAttempts int
+
+	// Backoff decides how long to wait before each retry. If nil, retries happen immediately.
+	Backoff pipedream.BackoffFunc
+
+	// RetryIf decides whether a failed attempt is retried. It is evaluated on the failed attempt's context,
+	// with the error saved under ErrorKey if it is set. If nil, all errors are retried.
+	RetryIf pipedream.Condition
+
+	// AttemptKey is the context key the attempt number, starting at 1, is saved to for the pipeline. Optional.
+	AttemptKey string
+
+	// ErrorKey is the context key the error of a failed attempt is saved to for RetryIf. Optional.
+	ErrorKey string
+
+	// Clock used for the backoff. Default pipedream.SystemClock.
+	Clock pipedream.Clock
+}
+
+// Execute runs the pipeline until an attempt succeeds or stops with pipedream.ErrPipelineExecutionStop,
+// then commits that attempt's context writes. Attempts are never retried once the execution is canceled.
+// If every attempt fails, ErrPipelineRetriesExhausted is returned wrapping the last error.
+func (r RetryNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	if r.Pipeline == nil {
+		return fmt.Errorf("%w: no pipeline to retry", pipedream.ErrNilNode)
+	}
+	clock := r.Clock
+	if clock == nil {
+		clock = pipedream.SystemClock{}
+	}
+	ctx := ectx.Context()
+
+	for attempt := 1; ; attempt++ {
+		apctx := pctx.Clone()
+		if r.AttemptKey != "" {
+			apctx.SetValue(r.AttemptKey, attempt)
+		}
+
+		err := r.Pipeline.Execute(ectx.Sub("Pipeline"), apctx)
+		if err == nil || errors.Is(err, pipedream.ErrPipelineExecutionStop) {
+			pctx.Merge(apctx)
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if r.ErrorKey != "" {
+			apctx.SetValue(r.ErrorKey, err)
+		}
+		if r.RetryIf != nil {
+			retry, condErr := ectx.EvaluateCondition(r.RetryIf, apctx)
+			if condErr != nil {
+				return errors.Join(err, fmt.Errorf("failed to evaluate retry condition: %w", condErr))
+			}
+			if !retry {
+				return err
+			}
+		}
+		if attempt >= r.MaxAttempts {
+			return fmt.Errorf("%w after %d attempts: %w", ErrPipelineRetriesExhausted, attempt, err)
+		}
+
+		select {
+		case <-clock.After(r.wait(attempt)):
+		case <-ctx.Done():
+			return fmt.Errorf("retry interrupted after %d attempts: %w", attempt, ctx.Err())
+		}
+	}
+}
+
+func (r RetryNode) wait(retry int) time.Duration {
+	if r.Backoff == nil {
+		return 0
+	}
+	return r.Backoff(retry)
+}
+
+// Validate implements the pipedream.Validator interface for RetryNode.
+// Keys written by the pipeline are visible to later nodes, since they only run if an attempt succeeded.
+func (r RetryNode) Validate(vctx pipedream.ValidationContext) {
+	if r.Pipeline == nil {
+		vctx.Sub("Pipeline").Report(pipedream.ErrNilNode)
+		return
+	}
+
+	if r.AttemptKey != "" {
+		vctx.DeclareKeyType(r.AttemptKey, reflect.TypeFor[int]())
+	}
+	vctx.Sub("Pipeline").ValidatePipeline(*r.Pipeline)
+
+	if r.RetryIf != nil {
+		cv := vctx.Clone()
+		if r.ErrorKey != "" {
+			cv.DeclareKey(r.ErrorKey)
+		}
+		cv.Sub("RetryIf").ValidateCondition(r.RetryIf)
+	}
+}
@@ -0,0 +1,148 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sidkurella/pipedream"
+)
+
+var errTransient = errors.New("transient")
+
+// waitClock records the waits it is asked for, and fires at once.
+type waitClock struct {
+	waits []time.Duration
+}
+
+func (c *waitClock) Now() time.Time {
+	return time.Time{}
+}
+
+func (c *waitClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+// flaky fails with errTransient before the given attempt, after writing the attempt it is on.
+// It fails differently if it sees a write from an earlier attempt.
+func flaky(until int) *pipedream.Pipeline {
+	return &pipedream.Pipeline{Nodes: []pipedream.Node{nodeFunc(func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+		v, _ := pctx.GetValue("attempt")
+		attempt := v.(int)
+		if written, ok := pctx.GetValue("written"); ok {
+			return fmt.Errorf("attempt %d saw the write of attempt %v", attempt, written)
+		}
+		pctx.SetValue("written", attempt)
+		if attempt < until {
+			return errTransient
+		}
+		return nil
+	})}}
+}
+
+func runRetry(t *testing.T, r RetryNode) (*pipedream.Report, error) {
+	t.Helper()
+	_, report, err := newExecutor().ExecuteWithReport(context.Background(), pipedream.Pipeline{Nodes: []pipedream.Node{r}})
+	return report, err
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	clock := &waitClock{}
+	report, err := runRetry(t, RetryNode{
+		Pipeline:    flaky(3),
+		MaxAttempts: 5,
+		Backoff:     pipedream.ExponentialBackoff(10*time.Millisecond, time.Second),
+		AttemptKey:  "attempt",
+		Clock:       clock,
+	})
+
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	// Only the successful attempt's writes are kept.
+	if report.Context["written"] != 3 || report.Context["attempt"] != 3 {
+		t.Errorf("Context = %v, want the writes of attempt 3", report.Context)
+	}
+	if want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}; !slices.Equal(clock.waits, want) {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	clock := &waitClock{}
+	report, err := runRetry(t, RetryNode{
+		Pipeline:    flaky(10),
+		MaxAttempts: 3,
+		AttemptKey:  "attempt",
+		Clock:       clock,
+	})
+
+	if !errors.Is(err, ErrPipelineRetriesExhausted) || !errors.Is(err, errTransient) {
+		t.Errorf("got %v, want ErrPipelineRetriesExhausted wrapping errTransient", err)
+	}
+	if _, ok := report.Context["written"]; ok {
+		t.Errorf("Context = %v, want no writes from failed attempts", report.Context)
+	}
+	if want := []time.Duration{0, 0}; !slices.Equal(clock.waits, want) {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+	errPermanent := errors.New("permanent")
+	attempts := 0
+	failing := func(err error) *pipedream.Pipeline {
+		return &pipedream.Pipeline{Nodes: []pipedream.Node{nodeFunc(func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+			attempts++
+			return err
+		})}}
+	}
+	retryIf := &pipedream.ErrorIsCondition{Error: &pipedream.DynamicValue{ContextKey: "err"}, Target: errTransient}
+
+	_, err := runRetry(t, RetryNode{Pipeline: failing(errPermanent), MaxAttempts: 3, RetryIf: retryIf, ErrorKey: "err", Clock: &waitClock{}})
+	if !errors.Is(err, errPermanent) || errors.Is(err, ErrPipelineRetriesExhausted) {
+		t.Errorf("got %v, want errPermanent without retrying", err)
+	}
+	if attempts != 1 {
+		t.Errorf("made %d attempts, want 1", attempts)
+	}
+
+	attempts = 0
+	_, err = runRetry(t, RetryNode{Pipeline: failing(errTransient), MaxAttempts: 3, RetryIf: retryIf, ErrorKey: "err", Clock: &waitClock{}})
+	if !errors.Is(err, ErrPipelineRetriesExhausted) || attempts != 3 {
+		t.Errorf("got %v after %d attempts, want ErrPipelineRetriesExhausted after 3", err, attempts)
+	}
+}
+
+func TestRetryStop(t *testing.T) {
+	attempts := 0
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		RetryNode{
+			Pipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{
+				nodeFunc(func(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+					attempts++
+					pctx.SetValue("x", 1)
+					return nil
+				}),
+				ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "x"}},
+			}},
+			MaxAttempts: 3,
+			Clock:       &waitClock{},
+		},
+		ReturnNode{ValueBuilder: literal("after")},
+	}}
+
+	v, report, err := newExecutor().ExecuteWithReport(context.Background(), p)
+	if err != nil || v != 1 || attempts != 1 {
+		t.Errorf("got %v, %v after %d attempts, want 1, nil after 1", v, err, attempts)
+	}
+	if report.StopReason != pipedream.StopReasonReturned || report.Context["x"] != 1 {
+		t.Errorf("stopped with %v and context %v, want returned with x committed", report.StopReason, report.Context)
+	}
+}
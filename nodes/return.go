@@ -1,8 +1,34 @@
 package nodes
 
-import "github.com/sidkurella/pipedream"
+import (
+	"fmt"
+
+	"github.com/sidkurella/pipedream"
+)
 
 // Builds a value and returns it as the end goal of the pipeline.
 type ReturnNode struct {
 	ValueBuilder pipedream.ValueBuilder
 }
+
+// Execute sets the built value as the return value of the execution and stops the pipeline.
+func (r ReturnNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	if r.ValueBuilder == nil {
+		return fmt.Errorf("%w: no value to return", pipedream.ErrNilValueBuilder)
+	}
+
+	v, err := r.ValueBuilder.Build(pctx)
+	if err != nil {
+		return fmt.Errorf("failed to build return value: %w", err)
+	}
+	ectx.SetReturnValue(v)
+
+	return pipedream.ErrPipelineExecutionStop
+}
+
+// Validate implements the pipedream.Validator interface for ReturnNode.
+// Nothing after a ReturnNode runs, so it marks the context as stopping.
+func (r ReturnNode) Validate(vctx pipedream.ValidationContext) {
+	vctx.Sub("ValueBuilder").ValidateValue(r.ValueBuilder)
+	vctx.MarkStops()
+}
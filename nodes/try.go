@@ -0,0 +1,92 @@
+package nodes
+
+import (
+	"errors"
+
+	"github.com/sidkurella/pipedream"
+)
+
+// Executes a pipeline, handling its errors with another pipeline, and runs a final pipeline no matter what.
+// All of the pipelines share the node's context.
+type TryNode struct {
+	// Pipeline to execute.
+	Body *pipedream.Pipeline
+
+	// Pipeline to execute if Body fails. If nil, errors from Body are returned once Finally has run.
+	// If Catch fails, its error is returned instead.
+	Catch *pipedream.Pipeline
+
+	// Pipeline to execute after Body and Catch, whether they succeeded or not.
+	// It also runs if the execution was canceled. Errors from Finally are joined with any error being returned.
+	Finally *pipedream.Pipeline
+
+	// ErrorKey is the context key to save the error from Body to, so Catch and later nodes can inspect it,
+	// e.g. with pipedream.ErrorIsCondition. It is set to nil if Body succeeds or stops. Optional.
+	ErrorKey string
+}
+
+// Execute runs Body, then Catch if Body failed, then Finally.
+// Stopping with pipedream.ErrPipelineExecutionStop is not a failure, so it is not caught; Finally still runs.
+func (t TryNode) Execute(ectx pipedream.ExecutionContext, pctx pipedream.PipelineContext) error {
+	var err error
+	if t.Body != nil {
+		err = t.Body.Execute(ectx.Sub("Body"), pctx)
+	}
+
+	failed := err != nil && !errors.Is(err, pipedream.ErrPipelineExecutionStop)
+	if t.ErrorKey != "" {
+		// Always written, so a stale error from an earlier run of the node does not linger.
+		var bodyErr error
+		if failed {
+			bodyErr = err
+		}
+		pctx.SetValue(t.ErrorKey, bodyErr)
+	}
+	if failed && t.Catch != nil {
+		err = t.Catch.Execute(ectx.Sub("Catch"), pctx)
+	}
+
+	if t.Finally == nil {
+		return err
+	}
+	finallyErr := t.Finally.Execute(ectx.Sub("Finally").WithoutCancel(), pctx)
+	switch {
+	case finallyErr == nil:
+		return err
+	case err == nil:
+		return finallyErr
+	case errors.Is(err, pipedream.ErrPipelineExecutionStop):
+		// Finally failing takes precedence over the body stopping.
+		if errors.Is(finallyErr, pipedream.ErrPipelineExecutionStop) {
+			return err
+		}
+		return finallyErr
+	default:
+		return errors.Join(err, finallyErr)
+	}
+}
+
+// Validate implements the pipedream.Validator interface for TryNode.
+// ErrorKey and keys written by Finally are visible to later nodes, as are keys written by both Body and Catch.
+// Without a Catch, keys written by Body are visible, since later nodes only run if Body succeeded.
+func (t TryNode) Validate(vctx pipedream.ValidationContext) {
+	bv := vctx.Sub("Body").Clone()
+	if t.Body != nil {
+		bv.ValidatePipeline(*t.Body)
+	}
+
+	if t.ErrorKey != "" {
+		vctx.DeclareKey(t.ErrorKey)
+	}
+	branches := []pipedream.ValidationContext{bv}
+	if t.Catch != nil {
+		cv := vctx.Sub("Catch").Clone()
+		cv.ValidatePipeline(*t.Catch)
+		branches = append(branches, cv)
+	}
+
+	if t.Finally != nil {
+		vctx.Sub("Finally").ValidatePipeline(*t.Finally)
+	}
+	vctx.MergeBranches(branches...)
+}
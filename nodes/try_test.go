@@ -0,0 +1,92 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sidkurella/pipedream"
+)
+
+func errorIs(key string, target error) pipedream.Condition {
+	return &pipedream.ErrorIsCondition{Error: &pipedream.DynamicValue{ContextKey: key}, Target: target}
+}
+
+// returnIf returns a pipeline returning whether the condition holds.
+func returnIf(c pipedream.Condition) pipedream.Node {
+	return BranchNode{
+		Condition:     c,
+		TruePipeline:  &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal(true)}}},
+		FalsePipeline: &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal(false)}}},
+	}
+}
+
+func TestTryCatchesError(t *testing.T) {
+	executor := newExecutor()
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		TryNode{
+			Body:     &pipedream.Pipeline{Nodes: []pipedream.Node{QueryNode{DataSourceName: "missing", Params: literal(1), SaveToName: "x"}}},
+			Catch:    &pipedream.Pipeline{Nodes: []pipedream.Node{returnIf(errorIs("err", pipedream.ErrDataSourceNotFound))}},
+			ErrorKey: "err",
+		},
+	}}
+
+	v, err := executor.Execute(context.Background(), p)
+	if err != nil || v != true {
+		t.Errorf("Execute = %v, %v, want true, nil", v, err)
+	}
+}
+
+func TestTryErrorKeyAfterSuccess(t *testing.T) {
+	executor := newExecutor()
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		TryNode{
+			Body:     &pipedream.Pipeline{Nodes: []pipedream.Node{QueryNode{DataSourceName: "double", Params: literal(1), SaveToName: "x"}}},
+			ErrorKey: "err",
+		},
+		returnIf(errorIs("err", pipedream.ErrDataSourceNotFound)),
+	}}
+
+	if diags := executor.Validate(p); len(diags) != 0 {
+		t.Errorf("Validate = %v, want no diagnostics", diags)
+	}
+	v, err := executor.Execute(context.Background(), p)
+	if err != nil || v != false {
+		t.Errorf("Execute = %v, %v, want false, nil", v, err)
+	}
+}
+
+func TestTryFinallyAlwaysRuns(t *testing.T) {
+	executor := newExecutor()
+	finally := &pipedream.Pipeline{Nodes: []pipedream.Node{QueryNode{DataSourceName: "double", Params: literal(1), SaveToName: "finally"}}}
+	readFinally := ReturnNode{ValueBuilder: &pipedream.DynamicValue{ContextKey: "finally"}}
+
+	// Without a Catch, the error is returned after Finally runs.
+	p := pipedream.Pipeline{Nodes: []pipedream.Node{
+		TryNode{Body: &pipedream.Pipeline{Nodes: []pipedream.Node{nil}}, Finally: finally},
+	}}
+	_, report, err := executor.ExecuteWithReport(context.Background(), p)
+	if !errors.Is(err, pipedream.ErrNilNode) {
+		t.Errorf("failing body: got %v, want ErrNilNode", err)
+	}
+	if report.Context["finally"] != 2 {
+		t.Error("failing body: Finally did not run")
+	}
+
+	// Stopping passes through untouched.
+	p = pipedream.Pipeline{Nodes: []pipedream.Node{
+		TryNode{
+			Body:    &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal("body")}}},
+			Catch:   &pipedream.Pipeline{Nodes: []pipedream.Node{ReturnNode{ValueBuilder: literal("catch")}}},
+			Finally: finally,
+		},
+		readFinally,
+	}}
+	v, report, err := executor.ExecuteWithReport(context.Background(), p)
+	if v != "body" {
+		t.Errorf("stopping body: returned %v, want body", v)
+	}
+	if err != nil || report.StopReason != pipedream.StopReasonReturned || report.Context["finally"] != 2 {
+		t.Errorf("stopping body: got %v, %v, %v, want returned after Finally", err, report.StopReason, report.Context)
+	}
+}
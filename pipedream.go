@@ -2,14 +2,68 @@ package pipedream
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
 var ErrDataSourceNotFound = fmt.Errorf("data source not found")
 
 type ExecutionContext struct {
+	ctx         context.Context
 	dataSources map[string]DataSource
-	returnValue any
+	ret         *returnSlot
+
+	path   string
+	tracer Tracer
+
+	executionID  string
+	pipelineName string
+	logger       *executionLogger
+	sensitive    []string
+	metrics      MetricsSink
+	report       *reportBuilder
+}
+
+// Context returns the context.Context of the current execution.
+func (e ExecutionContext) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// withContext returns a copy of the ExecutionContext using the given context.Context.
+func (e ExecutionContext) withContext(ctx context.Context) ExecutionContext {
+	e.ctx = ctx
+	return e
+}
+
+// WithoutCancel returns a copy of the ExecutionContext that is not canceled when the execution is,
+// for work that must run regardless, such as cleanup.
+func (e ExecutionContext) WithoutCancel() ExecutionContext {
+	return e.withContext(context.WithoutCancel(e.Context()))
+}
+
+// WithCancel returns a copy of the ExecutionContext that is canceled when the returned function is called,
+// e.g. to stop work running in parallel once part of it fails.
+func (e ExecutionContext) WithCancel() (ExecutionContext, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(e.Context())
+	return e.withContext(ctx), cancel
+}
+
+// Path returns the path of the node currently executing, e.g. "nodes[3].TruePipeline.nodes[1]".
+func (e ExecutionContext) Path() string {
+	return e.path
+}
+
+// Sub returns an ExecutionContext for a child element. The segment is appended to the current path.
+// Nodes that run nested pipelines should pass them a Sub context named after the field holding the pipeline.
+func (e ExecutionContext) Sub(segment string) ExecutionContext {
+	if e.path != "" {
+		segment = e.path + "." + segment
+	}
+	e.path = segment
+	return e
 }
 
 func (e ExecutionContext) GetDataSource(name string) (DataSource, error) {
@@ -18,7 +72,68 @@ func (e ExecutionContext) GetDataSource(name string) (DataSource, error) {
 		return DataSource{}, ErrDataSourceNotFound
 	}
 
-	return dataSource, nil
+	return e.traceDataSource(dataSource), nil
+}
+
+// EvaluateCondition evaluates a condition on behalf of the current node.
+// Nodes should evaluate their conditions through it so that evaluations are traced.
+func (e ExecutionContext) EvaluateCondition(c Condition, pctx PipelineContext) (bool, error) {
+	if c == nil {
+		return false, ErrNilCondition
+	}
+
+	cctx, end := e.Sub("Condition").startSpan(SpanCondition, typeName(c))
+	res, err := c.Evaluate(pctx)
+	end(SpanResult{Err: err, Result: res, Size: -1})
+	if err != nil {
+		return false, &conditionError{condition: typeName(c), path: cctx.path, err: err}
+	}
+	return res, nil
+}
+
+// returnSlot holds the value set by SetReturnValue.
+type returnSlot struct {
+	value    any
+	returned bool
+
+	// scoped is set for slots made by WithReturnScope, whose values are not returned from the execution.
+	scoped bool
+}
+
+// SetReturnValue sets the value returned from the current execution.
+// Nodes that set a return value should usually also return ErrPipelineExecutionStop.
+func (e ExecutionContext) SetReturnValue(v any) {
+	if e.ret != nil {
+		e.ret.value = v
+		e.ret.returned = true
+		if e.ret.scoped {
+			return
+		}
+	}
+	if e.report != nil {
+		e.report.setReturned()
+	}
+}
+
+// ReturnValue returns the value set by SetReturnValue, or nil if none was set.
+func (e ExecutionContext) ReturnValue() any {
+	if e.ret == nil {
+		return nil
+	}
+	return e.ret.value
+}
+
+// Returned reports whether SetReturnValue was called.
+func (e ExecutionContext) Returned() bool {
+	return e.ret != nil && e.ret.returned
+}
+
+// WithReturnScope returns a copy of the ExecutionContext with a return value of its own,
+// for nested pipelines that each produce a result, such as the elements of a map.
+// Setting the copy's return value does not set the execution's.
+func (e ExecutionContext) WithReturnScope() ExecutionContext {
+	e.ret = &returnSlot{scoped: true}
+	return e
 }
 
 type PipelineExecutor struct {
@@ -31,13 +146,98 @@ func NewPipelineExecutor() *PipelineExecutor {
 	}
 }
 
+// SetTracer sets the Tracer that receives spans for every execution. Use nil to disable tracing.
+func (p *PipelineExecutor) SetTracer(tracer Tracer) {
+	p.ectx.tracer = tracer
+}
+
 func (p *PipelineExecutor) RegisterDataSource(
 	source DataSource,
 ) {
 	p.ectx.dataSources[source.Name] = source
 }
 
-func (p *PipelineExecutor) Execute(ctx context.Context, pipeline Pipeline) (any, error) {
-	// TODO
-	return nil, nil
+// Execute runs the pipeline with a fresh PipelineContext and returns the value set by a ReturnNode, if any.
+// Stopping early with ErrPipelineExecutionStop is not considered an error.
+// Options only apply to this execution and do not affect the executor or other executions.
+func (p *PipelineExecutor) Execute(ctx context.Context, pipeline Pipeline, opts ...ExecuteOption) (any, error) {
+	v, err := p.execute(ctx, pipeline, NewPipelineContext(), opts, nil)
+	if err != nil && !errors.Is(err, ErrPipelineExecutionStop) {
+		return nil, err
+	}
+	return v, nil
+}
+
+// execute runs the pipeline with the given context. ErrPipelineExecutionStop is returned unchanged.
+// If setup is not nil, it can adjust the ExecutionContext before the pipeline runs.
+func (p *PipelineExecutor) execute(
+	ctx context.Context,
+	pipeline Pipeline,
+	pctx PipelineContext,
+	opts []ExecuteOption,
+	setup func(ectx *ExecutionContext),
+) (any, error) {
+	config := newExecuteConfig(opts)
+	dataSources, err := config.dataSources(p.ectx.dataSources)
+	if err != nil {
+		return nil, err
+	}
+
+	ectx := p.ectx
+	ectx.ctx = ctx
+	ectx.dataSources = dataSources
+	ectx.ret = &returnSlot{}
+	ectx.tracer = config.tracer(p.ectx.tracer)
+	ectx.executionID = newExecutionID()
+	ectx.pipelineName = pipeline.Name
+	if setup != nil {
+		setup(&ectx)
+	}
+
+	ectx, end := ectx.startSpan(SpanPipeline, "pipeline")
+	err = pipeline.Execute(ectx, pctx)
+	end(SpanResult{Err: err, Size: -1})
+	if err != nil && !errors.Is(err, ErrPipelineExecutionStop) {
+		return nil, err
+	}
+
+	return ectx.ReturnValue(), err
+}
+
+// Validate statically checks the pipeline against the registered data sources without executing it.
+// The data source options of Execute, such as WithAdditionalDataSources, apply in the same way.
+// All problems found are returned together. An empty result means no problems were found.
+func (p *PipelineExecutor) Validate(pipeline Pipeline, opts ...ExecuteOption) []Diagnostic {
+	dataSources, err := newExecuteConfig(opts).dataSources(p.ectx.dataSources)
+	if err != nil {
+		return []Diagnostic{{Err: err}}
+	}
+
+	vctx := newValidationContext(dataSources)
+	vctx.ValidatePipeline(pipeline)
+	return *vctx.diagnostics
+}
+
+// WithRecorder returns a copy of the executor whose registered data sources are captured by the recorder.
+// Data sources registered on the copy afterwards are not recorded.
+func (p *PipelineExecutor) WithRecorder(r *Recorder) *PipelineExecutor {
+	return p.mapDataSources(r.Record)
+}
+
+// WithReplayer returns a copy of the executor whose registered data sources serve recorded calls
+// instead of calling their getters.
+func (p *PipelineExecutor) WithReplayer(r *Replayer) *PipelineExecutor {
+	return p.mapDataSources(r.Replay)
+}
+
+func (p *PipelineExecutor) mapDataSources(f func(DataSource) DataSource) *PipelineExecutor {
+	c := NewPipelineExecutor()
+	c.ectx.tracer = p.ectx.tracer
+	c.ectx.logger = p.ectx.logger
+	c.ectx.sensitive = p.ectx.sensitive
+	c.ectx.metrics = p.ectx.metrics
+	for _, source := range p.ectx.dataSources {
+		c.RegisterDataSource(f(source))
+	}
+	return c
 }
@@ -0,0 +1,242 @@
+// Package pipedreamtest provides fake data sources for testing pipelines.
+//
+// A FakeDataSource is built on pipedream.NewDataSource, so pipelines run against it unchanged:
+//
+//	users := pipedreamtest.NewFakeDataSource[int, User]("users")
+//	users.ExpectQuery(1).Return(User{Name: "alice"})
+//	users.OnMatch(func(id int) bool { return id < 0 }).ReturnError(ErrInvalidID)
+//	users.Register(executor)
+//
+//	result, err := executor.Execute(ctx, pipeline)
+//	users.Verify(t)
+package pipedreamtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sidkurella/pipedream"
+)
+
+var ErrUnexpectedQuery = fmt.Errorf("unexpected query to fake data source")
+var ErrExpectationsNotMet = fmt.Errorf("fake data source expectations not met")
+
+// FakeDataSource serves canned responses for params matching its rules.
+// Rules are checked in the order they were added, and the first matching rule serves the call.
+// A query that matches no rule fails with ErrUnexpectedQuery.
+type FakeDataSource[T any, U any] struct {
+	name  string
+	clock pipedream.Clock
+
+	mu    sync.Mutex
+	rules []*Rule[T, U]
+	calls []T
+}
+
+// Rule describes how a FakeDataSource responds to matching params.
+// Rules may be changed while the fake is in use.
+type Rule[T any, U any] struct {
+	fake        *FakeDataSource[T, U]
+	description string
+	match       func(params T) bool
+
+	responses []fakeResponse[U]
+	latency   time.Duration
+
+	// expected is the number of calls expected, or -1 if any number of calls is allowed.
+	expected int
+	calls    int
+}
+
+type fakeResponse[U any] struct {
+	value U
+	err   error
+}
+
+func NewFakeDataSource[T any, U any](name string) *FakeDataSource[T, U] {
+	return &FakeDataSource[T, U]{
+		name:  name,
+		clock: pipedream.SystemClock{},
+	}
+}
+
+// WithClock sets the Clock used to simulate latency. The default is pipedream.SystemClock.
+func (f *FakeDataSource[T, U]) WithClock(clock pipedream.Clock) *FakeDataSource[T, U] {
+	f.clock = clock
+	return f
+}
+
+// On adds a rule for params deeply equal to the given params.
+func (f *FakeDataSource[T, U]) On(params T) *Rule[T, U] {
+	return f.addRule(fmt.Sprintf("%+v", params), func(p T) bool {
+		return reflect.DeepEqual(p, params)
+	})
+}
+
+// OnMatch adds a rule for params accepted by the matcher.
+func (f *FakeDataSource[T, U]) OnMatch(match func(params T) bool) *Rule[T, U] {
+	return f.addRule("matcher", match)
+}
+
+// OnAny adds a rule matching any params.
+func (f *FakeDataSource[T, U]) OnAny() *Rule[T, U] {
+	return f.addRule("any params", func(T) bool { return true })
+}
+
+// ExpectQuery adds a rule for params deeply equal to the given params that must be called exactly once.
+// Use Times to expect a different number of calls.
+func (f *FakeDataSource[T, U]) ExpectQuery(params T) *Rule[T, U] {
+	return f.On(params).Times(1)
+}
+
+// Return adds a response. Successive calls are served successive responses, and the last one repeats.
+func (r *Rule[T, U]) Return(resp U) *Rule[T, U] {
+	r.fake.mu.Lock()
+	defer r.fake.mu.Unlock()
+
+	r.responses = append(r.responses, fakeResponse[U]{value: resp})
+	return r
+}
+
+// ReturnError adds an error response. Successive calls are served successive responses, and the last one repeats.
+func (r *Rule[T, U]) ReturnError(err error) *Rule[T, U] {
+	r.fake.mu.Lock()
+	defer r.fake.mu.Unlock()
+
+	r.responses = append(r.responses, fakeResponse[U]{err: err})
+	return r
+}
+
+// Delay makes every matching call take the given duration, or until its context is done.
+func (r *Rule[T, U]) Delay(latency time.Duration) *Rule[T, U] {
+	r.fake.mu.Lock()
+	defer r.fake.mu.Unlock()
+
+	r.latency = latency
+	return r
+}
+
+// Times expects the rule to serve exactly n calls. Once n calls were served, the rule stops matching.
+func (r *Rule[T, U]) Times(n int) *Rule[T, U] {
+	r.fake.mu.Lock()
+	defer r.fake.mu.Unlock()
+
+	r.expected = n
+	return r
+}
+
+// DataSource returns a pipedream.DataSource backed by the fake.
+func (f *FakeDataSource[T, U]) DataSource() pipedream.DataSource {
+	return pipedream.NewDataSource(f.name, f.get)
+}
+
+// Register registers the fake on the executor.
+func (f *FakeDataSource[T, U]) Register(executor *pipedream.PipelineExecutor) {
+	executor.RegisterDataSource(f.DataSource())
+}
+
+// Calls returns the params of every call made so far, in order, including unexpected ones.
+func (f *FakeDataSource[T, U]) Calls() []T {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]T{}, f.calls...)
+}
+
+// CallCount returns the number of calls made so far, including unexpected ones.
+func (f *FakeDataSource[T, U]) CallCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.calls)
+}
+
+// ExpectationsMet returns an error describing every rule that did not serve the expected number of calls.
+func (f *FakeDataSource[T, U]) ExpectationsMet() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for _, r := range f.rules {
+		if r.expected >= 0 && r.calls != r.expected {
+			errs = append(errs, fmt.Errorf("%w: %s: query with %s expected %d calls, got %d",
+				ErrExpectationsNotMet, f.name, r.description, r.expected, r.calls))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verify fails the test if any expectations were not met.
+func (f *FakeDataSource[T, U]) Verify(t testing.TB) {
+	t.Helper()
+	if err := f.ExpectationsMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Expecter is implemented by fakes with expectations, regardless of their param and response types.
+type Expecter interface {
+	ExpectationsMet() error
+}
+
+// VerifyAll fails the test if any of the fakes' expectations were not met.
+func VerifyAll(t testing.TB, fakes ...Expecter) {
+	t.Helper()
+	for _, f := range fakes {
+		if err := f.ExpectationsMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func (f *FakeDataSource[T, U]) addRule(description string, match func(params T) bool) *Rule[T, U] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := &Rule[T, U]{fake: f, description: description, match: match, expected: -1}
+	f.rules = append(f.rules, r)
+	return r
+}
+
+func (f *FakeDataSource[T, U]) get(ctx context.Context, params T) (U, error) {
+	resp, latency, err := f.serve(params)
+	if latency > 0 {
+		select {
+		case <-f.clock.After(latency):
+		case <-ctx.Done():
+			var zeroU U
+			return zeroU, ctx.Err()
+		}
+	}
+	return resp.value, err
+}
+
+// serve finds the rule for the params and records the call.
+func (f *FakeDataSource[T, U]) serve(params T) (fakeResponse[U], time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, params)
+	for _, r := range f.rules {
+		if r.expected >= 0 && r.calls >= r.expected {
+			continue
+		}
+		if !r.match(params) {
+			continue
+		}
+
+		r.calls++
+		if len(r.responses) == 0 {
+			return fakeResponse[U]{}, r.latency, nil
+		}
+		resp := r.responses[min(r.calls, len(r.responses))-1]
+		return resp, r.latency, resp.err
+	}
+
+	return fakeResponse[U]{}, 0, fmt.Errorf("%w: %s with params %+v", ErrUnexpectedQuery, f.name, params)
+}
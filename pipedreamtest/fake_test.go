@@ -0,0 +1,186 @@
+package pipedreamtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errFake = errors.New("fake failure")
+
+// manualClock hands each timer's channel to the test to fire.
+type manualClock struct {
+	timers chan chan time.Time
+}
+
+func (c manualClock) Now() time.Time {
+	return time.Time{}
+}
+
+func (c manualClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.timers <- ch
+	return ch
+}
+
+// recordingTB records the errors reported to it instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Error(args ...any) {
+	r.errs = append(r.errs, fmt.Sprint(args...))
+}
+
+func get[T any, U any](f *FakeDataSource[T, U], ctx context.Context, params T) (any, error) {
+	return f.DataSource().Get(ctx, params)
+}
+
+func TestFakeSequentialResponses(t *testing.T) {
+	fake := NewFakeDataSource[int, string]("users")
+	fake.On(1).Return("a").Return("b").ReturnError(errFake)
+
+	for i, want := range []string{"a", "b"} {
+		if v, err := get(fake, context.Background(), 1); err != nil || v != want {
+			t.Errorf("call %d = %v, %v, want %q, nil", i+1, v, err, want)
+		}
+	}
+	// The last response repeats.
+	for range 2 {
+		if _, err := get(fake, context.Background(), 1); !errors.Is(err, errFake) {
+			t.Errorf("got %v, want errFake", err)
+		}
+	}
+	if got := fake.Calls(); !slices.Equal(got, []int{1, 1, 1, 1}) {
+		t.Errorf("Calls = %v, want four calls with 1", got)
+	}
+}
+
+func TestFakeTimes(t *testing.T) {
+	fake := NewFakeDataSource[int, string]("users")
+	fake.On(1).Times(1).Return("once")
+
+	if v, err := get(fake, context.Background(), 1); err != nil || v != "once" {
+		t.Errorf("first call = %v, %v, want once, nil", v, err)
+	}
+	if _, err := get(fake, context.Background(), 1); !errors.Is(err, ErrUnexpectedQuery) {
+		t.Errorf("second call: got %v, want ErrUnexpectedQuery", err)
+	}
+
+	// Later rules serve calls once earlier ones are exhausted.
+	fake.OnAny().Return("any")
+	if v, err := get(fake, context.Background(), 1); err != nil || v != "any" {
+		t.Errorf("third call = %v, %v, want any, nil", v, err)
+	}
+	if fake.CallCount() != 3 {
+		t.Errorf("CallCount = %d, want 3", fake.CallCount())
+	}
+}
+
+func TestFakeUnexpectedQuery(t *testing.T) {
+	fake := NewFakeDataSource[int, string]("users")
+	fake.OnMatch(func(id int) bool { return id > 0 }).Return("positive")
+
+	if _, err := get(fake, context.Background(), -1); !errors.Is(err, ErrUnexpectedQuery) {
+		t.Errorf("got %v, want ErrUnexpectedQuery", err)
+	}
+	if v, err := get(fake, context.Background(), 2); err != nil || v != "positive" {
+		t.Errorf("got %v, %v, want positive, nil", v, err)
+	}
+}
+
+func TestFakeDelay(t *testing.T) {
+	clock := manualClock{timers: make(chan chan time.Time)}
+	fake := NewFakeDataSource[int, string]("users").WithClock(clock)
+	fake.OnAny().Return("slow").Delay(time.Second)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := get(fake, context.Background(), 1)
+		done <- err
+	}()
+	timer := <-clock.timers
+	select {
+	case err := <-done:
+		t.Fatalf("call returned %v before the delay elapsed", err)
+	default:
+	}
+	timer <- time.Time{}
+	if err := <-done; err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, err := get(fake, ctx, 1)
+		done <- err
+	}()
+	<-clock.timers
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled call: got %v, want context.Canceled", err)
+	}
+}
+
+func TestFakeVerify(t *testing.T) {
+	users := NewFakeDataSource[int, string]("users")
+	users.ExpectQuery(1).Return("a")
+	users.On(2).Times(2).Return("b")
+	orders := NewFakeDataSource[string, int]("orders")
+	orders.ExpectQuery("x").Return(1)
+
+	get(users, context.Background(), 1)
+	get(users, context.Background(), 2)
+	get(orders, context.Background(), "x")
+
+	if err := users.ExpectationsMet(); !errors.Is(err, ErrExpectationsNotMet) {
+		t.Errorf("ExpectationsMet = %v, want ErrExpectationsNotMet", err)
+	}
+	if err := orders.ExpectationsMet(); err != nil {
+		t.Errorf("ExpectationsMet = %v, want nil", err)
+	}
+
+	tb := &recordingTB{}
+	users.Verify(tb)
+	if len(tb.errs) != 1 {
+		t.Errorf("Verify reported %v, want one error for query 2", tb.errs)
+	}
+
+	tb = &recordingTB{}
+	VerifyAll(tb, users, orders)
+	if len(tb.errs) != 1 {
+		t.Errorf("VerifyAll reported %v, want one error for users", tb.errs)
+	}
+
+	get(users, context.Background(), 2)
+	tb = &recordingTB{}
+	VerifyAll(tb, users, orders)
+	if len(tb.errs) != 0 {
+		t.Errorf("VerifyAll reported %v, want no errors", tb.errs)
+	}
+}
+
+func TestFakeRulesChangedWhileInUse(t *testing.T) {
+	fake := NewFakeDataSource[int, int]("numbers")
+	fake.OnAny().Return(0)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			get(fake, context.Background(), 1)
+		}
+	}()
+	for i := range 100 {
+		fake.On(i).Return(i).Times(1).Delay(0)
+	}
+	wg.Wait()
+}
@@ -1,10 +1,14 @@
 package pipedream
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // Sentinel error to return to stop pipeline execution.
 // Otherwise, the pipeline will proceed to the next node in the chain.
 var ErrPipelineExecutionStop = fmt.Errorf("pipeline execution stop")
+var ErrNilNode = fmt.Errorf("nil node provided")
 
 // Shared interface for nodes.
 // If you want you can define your own that fits this pattern.
@@ -15,4 +19,73 @@ type Node interface {
 // A Pipeline is made up of a sequence of nodes that are executed in order.
 type Pipeline struct {
 	Nodes []Node
+
+	// Name identifies the pipeline in logs. Optional.
+	Name string
+
+	// ErrorPolicy decides what happens when a node fails, unless the node is wrapped in an ErrorPolicyNode.
+	// Default FailFast.
+	ErrorPolicy ErrorPolicy
+}
+
+// Execute implements the Node interface for Pipeline, so pipelines can be nested inside other pipelines.
+// It runs each node in order. Errors are wrapped in a PipelineError and handled by the error policy,
+// which by default stops at the first error.
+// ErrPipelineExecutionStop is returned unchanged so that enclosing pipelines stop as well,
+// unless errors were collected by ContinueOnError, in which case those are returned instead.
+func (p Pipeline) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	var collected []error
+	// The keys each node uses are recorded, so a PipelineError only needs a snapshot of those.
+	pctx = pctx.trackAccess()
+	for i, node := range p.Nodes {
+		clear(pctx.accessed)
+		if node == nil {
+			return withCollected(collected, fmt.Errorf("%w: nodes[%d]", ErrNilNode, i))
+		}
+		if err := ectx.Context().Err(); err != nil {
+			return withCollected(collected, err)
+		}
+
+		nctx, end := ectx.Sub(fmt.Sprintf("nodes[%d]", i)).startNodeSpan(nodeTypeName(node), pctx)
+		err := node.Execute(nctx, pctx)
+		end(SpanResult{Err: err, Size: -1})
+		if errors.Is(err, ErrPipelineExecutionStop) {
+			if len(collected) > 0 {
+				return errors.Join(collected...)
+			}
+			return err
+		}
+		if err == nil {
+			continue
+		}
+
+		pe := newPipelineError(nctx, node, pctx, err)
+		if isCanceled(ectx) {
+			return withCollected(collected, pe)
+		}
+		var info *PipelineError
+		errors.As(pe, &info)
+		_, own := innerNode[ErrorPolicyNode](node)
+		collect, stop := p.errorPolicyFor(node).handle(node, own, pctx, pe, info)
+		if stop != nil {
+			return withCollected(collected, stop)
+		}
+		if collect {
+			collected = append(collected, pe)
+		}
+	}
+	return errors.Join(collected...)
+}
+
+// withCollected returns err, joined with the errors collected by ContinueOnError if there are any.
+func withCollected(collected []error, err error) error {
+	if len(collected) == 0 {
+		return err
+	}
+	return errors.Join(append(collected, err)...)
+}
+
+// Validate implements the Validator interface for Pipeline.
+func (p Pipeline) Validate(vctx ValidationContext) {
+	vctx.ValidatePipeline(p)
 }
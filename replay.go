@@ -0,0 +1,276 @@
+package pipedream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var ErrRecordingNotFound = fmt.Errorf("no recording matches data source query")
+var ErrRecordingTypeMismatch = fmt.Errorf("recorded response type does not match data source")
+
+// Recording is a sequence of data source calls captured by a Recorder.
+type Recording struct {
+	Calls []RecordedCall `json:"calls"`
+}
+
+// RecordedCall is a single captured data source call.
+type RecordedCall struct {
+	Source string `json:"source"`
+
+	// Key identifies the params, as derived by HashParams.
+	Key string `json:"key"`
+
+	Params   TypedValue `json:"params"`
+	Response TypedValue `json:"response"`
+
+	// Error is the message of the error returned, if any.
+	Error string `json:"error,omitempty"`
+
+	Latency time.Duration `json:"latency"`
+}
+
+// TypedValue is a JSON encoded value along with the name of its Go type.
+type TypedValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ReplayedError is returned in place of an error captured in a recording.
+// Only the message survives recording, so sentinel errors can not be matched with errors.Is.
+type ReplayedError struct {
+	Message string
+}
+
+func (e *ReplayedError) Error() string {
+	return e.Message
+}
+
+// ReadRecording reads a recording written by Recorder.WriteFile.
+func ReadRecording(path string) (Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Recording{}, err
+	}
+
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return Recording{}, fmt.Errorf("failed to decode recording %s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// Recorder captures every call made through the data sources it wraps, so they can be replayed later.
+// Stream data sources are passed through without being recorded.
+type Recorder struct {
+	clock Clock
+
+	mu    sync.Mutex
+	calls []RecordedCall
+	errs  []error
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{clock: SystemClock{}}
+}
+
+// Record returns a copy of the data source whose calls are captured by the recorder.
+func (r *Recorder) Record(d DataSource) DataSource {
+	if d.IsStream() {
+		return d
+	}
+
+	return d.WithMiddleware(recordingMiddleware{recorder: r, source: d.Name})
+}
+
+type recordingMiddleware struct {
+	recorder *Recorder
+	source   string
+}
+
+func (m recordingMiddleware) Wrap(next GetFunc) GetFunc {
+	return func(ctx context.Context, params any) (any, error) {
+		start := m.recorder.clock.Now()
+		resp, err := next(ctx, params)
+		m.recorder.add(m.source, params, resp, err, m.recorder.clock.Now().Sub(start))
+		return resp, err
+	}
+}
+
+func (m recordingMiddleware) String() string {
+	return "Recorder"
+}
+
+// Recording returns the calls captured so far.
+func (r *Recorder) Recording() Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Recording{Calls: append([]RecordedCall{}, r.calls...)}
+}
+
+// Err returns the errors encountered encoding calls, if any. Calls that could not be encoded are not recorded.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return errors.Join(r.errs...)
+}
+
+// WriteFile writes the calls captured so far to a JSON file.
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r.Recording(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (r *Recorder) add(source string, params any, resp any, respErr error, latency time.Duration) {
+	call, err := newRecordedCall(source, params, resp, respErr, latency)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("failed to record call to %s: %w", source, err))
+		return
+	}
+	r.calls = append(r.calls, call)
+}
+
+func newRecordedCall(source string, params any, resp any, respErr error, latency time.Duration) (RecordedCall, error) {
+	key, err := HashParams(params)
+	if err != nil {
+		return RecordedCall{}, err
+	}
+	paramsValue, err := newTypedValue(params)
+	if err != nil {
+		return RecordedCall{}, fmt.Errorf("encoding params: %w", err)
+	}
+	respValue, err := newTypedValue(resp)
+	if err != nil {
+		return RecordedCall{}, fmt.Errorf("encoding response: %w", err)
+	}
+
+	call := RecordedCall{
+		Source:   source,
+		Key:      key,
+		Params:   paramsValue,
+		Response: respValue,
+		Latency:  latency,
+	}
+	if respErr != nil {
+		call.Error = respErr.Error()
+	}
+	return call, nil
+}
+
+func newTypedValue(v any) (TypedValue, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return TypedValue{}, err
+	}
+
+	typeName := "nil"
+	if v != nil {
+		typeName = reflect.TypeOf(v).String()
+	}
+	return TypedValue{Type: typeName, Value: data}, nil
+}
+
+// Replayer serves recorded calls in place of calling data source getters.
+// Calls with the same data source and params are served in the order they were recorded.
+// A query with no remaining recording fails with ErrRecordingNotFound.
+type Replayer struct {
+	mu    sync.Mutex
+	calls map[string][]RecordedCall
+}
+
+func NewReplayer(rec Recording) *Replayer {
+	calls := map[string][]RecordedCall{}
+	for _, call := range rec.Calls {
+		k := replayKey(call.Source, call.Key)
+		calls[k] = append(calls[k], call)
+	}
+	return &Replayer{calls: calls}
+}
+
+// Replay returns a copy of the data source that serves recorded calls instead of calling its getter.
+// The getter and everything wrapped around it are bypassed entirely.
+func (r *Replayer) Replay(d DataSource) DataSource {
+	d.wrappers = []string{"Replayer"}
+	d.healthChecks = nil
+	d.call = func(ctx context.Context, params any) (any, error) {
+		key, err := HashParams(params)
+		if err != nil {
+			return nil, err
+		}
+
+		call, ok := r.next(d.Name, key)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s with params %+v", ErrRecordingNotFound, d.Name, params)
+		}
+
+		resp, err := decodeTypedValue(call.Response, d.responseType)
+		if err != nil {
+			return nil, fmt.Errorf("replaying %s: %w", d.Name, err)
+		}
+		if call.Error != "" {
+			return resp, &ReplayedError{Message: call.Error}
+		}
+		return resp, nil
+	}
+	return d
+}
+
+// Unused returns the recorded calls that have not been served.
+func (r *Replayer) Unused() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []RecordedCall
+	for _, calls := range r.calls {
+		unused = append(unused, calls...)
+	}
+	return unused
+}
+
+func (r *Replayer) next(source string, key string) (RecordedCall, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := replayKey(source, key)
+	calls := r.calls[k]
+	if len(calls) == 0 {
+		return RecordedCall{}, false
+	}
+	r.calls[k] = calls[1:]
+	return calls[0], true
+}
+
+func replayKey(source string, key string) string {
+	return source + "\x00" + key
+}
+
+// decodeTypedValue decodes a recorded value into the response type of a data source.
+// Values recorded for interface response types are decoded generically, as encoding/json would into an any.
+func decodeTypedValue(tv TypedValue, t reflect.Type) (any, error) {
+	if tv.Type == "nil" {
+		return reflect.Zero(t).Interface(), nil
+	}
+	if t.Kind() != reflect.Interface && tv.Type != t.String() {
+		return nil, fmt.Errorf("%w: recorded %s, expected %s", ErrRecordingTypeMismatch, tv.Type, t)
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(tv.Value, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
@@ -0,0 +1,129 @@
+package pipedream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type replayUser struct {
+	Name string
+	Tags []string
+}
+
+func TestRecordReplay(t *testing.T) {
+	live := NewPipelineExecutor()
+	calls := 0
+	live.RegisterDataSource(NewDataSource("count", func(ctx context.Context, params string) (int, error) {
+		calls++
+		return calls, nil
+	}))
+	live.RegisterDataSource(NewDataSource("user", func(ctx context.Context, params int) (replayUser, error) {
+		calls++
+		return replayUser{Name: "ann", Tags: []string{"admin"}}, nil
+	}))
+	live.RegisterDataSource(NewDataSource("untyped", func(ctx context.Context, params int) (any, error) {
+		calls++
+		return map[string]any{"n": 1}, nil
+	}))
+	live.RegisterDataSource(NewDataSource("failing", func(ctx context.Context, params int) (int, error) {
+		calls++
+		return 0, errNode
+	}))
+
+	recorder := NewRecorder()
+	recording := live.WithRecorder(recorder)
+	for _, params := range []string{"a", "a", "b"} {
+		if _, err := Query[string, int](recording.ectx, "count", params); err != nil {
+			t.Fatalf("recording Query(count, %q) returned error: %v", params, err)
+		}
+	}
+	if _, err := Query[int, replayUser](recording.ectx, "user", 1); err != nil {
+		t.Fatalf("recording Query(user) returned error: %v", err)
+	}
+	if _, err := Query[int, any](recording.ectx, "untyped", 1); err != nil {
+		t.Fatalf("recording Query(untyped) returned error: %v", err)
+	}
+	if _, err := Query[int, int](recording.ectx, "failing", 1); !errors.Is(err, errNode) {
+		t.Fatalf("recording Query(failing): got %v, want errNode", err)
+	}
+	if err := recorder.Err(); err != nil {
+		t.Fatalf("recorder failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.json")
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	rec, err := ReadRecording(path)
+	if err != nil {
+		t.Fatalf("ReadRecording returned error: %v", err)
+	}
+	if got := rec.Calls[3].Response.Type; got != "pipedream.replayUser" {
+		t.Errorf("recorded response type = %q, want pipedream.replayUser", got)
+	}
+
+	replayer := NewReplayer(rec)
+	replaying := live.WithReplayer(replayer)
+	calls = 0
+
+	// Calls with the same params are served in the order they were recorded.
+	for _, want := range []int{1, 2} {
+		if v, err := Query[string, int](replaying.ectx, "count", "a"); err != nil || v != want {
+			t.Errorf("count(a) = %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := Query[string, int](replaying.ectx, "count", "a"); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("count(a) a third time: got %v, want ErrRecordingNotFound", err)
+	}
+	if _, err := Query[string, int](replaying.ectx, "count", "c"); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("count(c): got %v, want ErrRecordingNotFound", err)
+	}
+
+	u, err := Query[int, replayUser](replaying.ectx, "user", 1)
+	if want := (replayUser{Name: "ann", Tags: []string{"admin"}}); err != nil || !reflect.DeepEqual(u, want) {
+		t.Errorf("user = %+v, %v, want %+v, nil", u, err, want)
+	}
+
+	// Responses recorded for interface types come back as encoding/json decodes them into an any.
+	a, err := Query[int, any](replaying.ectx, "untyped", 1)
+	if want := map[string]any{"n": float64(1)}; err != nil || !reflect.DeepEqual(a, want) {
+		t.Errorf("untyped = %v, %v, want %v, nil", a, err, want)
+	}
+
+	var replayed *ReplayedError
+	if _, err := Query[int, int](replaying.ectx, "failing", 1); !errors.As(err, &replayed) || replayed.Message != errNode.Error() {
+		t.Errorf("failing: got %v, want ReplayedError %q", err, errNode)
+	}
+
+	if calls != 0 {
+		t.Errorf("getters called %d times while replaying, want 0", calls)
+	}
+	unused := replayer.Unused()
+	if len(unused) != 1 || unused[0].Source != "count" || string(unused[0].Params.Value) != `"b"` {
+		t.Errorf("Unused = %+v, want the call to count(b)", unused)
+	}
+}
+
+func TestReplayTypeMismatch(t *testing.T) {
+	live := NewPipelineExecutor()
+	live.RegisterDataSource(NewDataSource("double", double))
+
+	key, err := HashParams(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := Recording{Calls: []RecordedCall{{
+		Source:   "double",
+		Key:      key,
+		Response: TypedValue{Type: "string", Value: json.RawMessage(`"2"`)},
+	}}}
+
+	replaying := live.WithReplayer(NewReplayer(rec))
+	if _, err := Query[int, int](replaying.ectx, "double", 1); !errors.Is(err, ErrRecordingTypeMismatch) {
+		t.Errorf("got %v, want ErrRecordingTypeMismatch", err)
+	}
+}
@@ -0,0 +1,202 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// StopReason describes how an execution ended.
+type StopReason int
+
+const (
+	StopReasonCompleted StopReason = iota // Every node ran.
+	StopReasonReturned                    // A node set a return value and stopped, e.g. a ReturnNode.
+	StopReasonStopped                     // A node stopped the pipeline with ErrPipelineExecutionStop without returning a value.
+	StopReasonError                       // A node failed.
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopReasonCompleted:
+		return "completed"
+	case StopReasonReturned:
+		return "returned"
+	case StopReasonStopped:
+		return "stopped"
+	case StopReasonError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+func (r StopReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Report describes what happened during an execution. It can be marshaled to JSON, e.g. to attach to a support ticket.
+type Report struct {
+	ExecutionID string `json:"execution_id"`
+	Pipeline    string `json:"pipeline,omitempty"`
+
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration"`
+
+	StopReason StopReason `json:"stop_reason"`
+
+	// Error is the error the execution failed with, if any.
+	Error string `json:"error,omitempty"`
+
+	// Nodes are the nodes that ran, including those in nested pipelines, in the order they started.
+	Nodes []NodeReport `json:"nodes"`
+
+	// SkippedBranches are the paths of nested pipelines that were not run because a branch took the other way.
+	SkippedBranches []string `json:"skipped_branches,omitempty"`
+
+	// DataSourceCalls are the data source calls made, in the order they started.
+	DataSourceCalls []DataSourceCallReport `json:"data_source_calls,omitempty"`
+
+	// Context is a snapshot of the pipeline context at the end of the execution.
+	// Values marked sensitive or listed in LogConfig.SensitiveKeys are replaced by RedactedValue.
+	Context map[string]any `json:"context"`
+}
+
+// NodeReport describes a node that ran.
+type NodeReport struct {
+	Path      string        `json:"path"`
+	Type      string        `json:"type"`
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration"`
+
+	// Stopped is true if the node stopped the pipeline with ErrPipelineExecutionStop.
+	Stopped bool   `json:"stopped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DataSourceCallReport describes a single data source call.
+type DataSourceCallReport struct {
+	// Path of the node making the call.
+	Path string `json:"path"`
+	Name string `json:"name"`
+
+	// Params are the params after conversion to the data source's param type.
+	// If any of their fields or map entries are named in LogConfig.SensitiveKeys,
+	// they are a map[string]any with those values replaced by RedactedValue.
+	Params any `json:"params"`
+
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// reportBuilder collects a Report during an execution. Data sources may be called concurrently, so it is locked.
+type reportBuilder struct {
+	mu       sync.Mutex
+	report   Report
+	returned bool
+}
+
+// ExecuteWithReport is like Execute, but also returns a Report of the execution.
+// The report is returned even if the execution fails.
+func (p *PipelineExecutor) ExecuteWithReport(ctx context.Context, pipeline Pipeline, opts ...ExecuteOption) (any, *Report, error) {
+	rb := &reportBuilder{}
+	pctx := NewPipelineContext()
+	start := time.Now()
+
+	v, err := p.execute(ctx, pipeline, pctx, opts, func(ectx *ExecutionContext) {
+		ectx.report = rb
+		rb.report.ExecutionID = ectx.executionID
+	})
+	stopped := errors.Is(err, ErrPipelineExecutionStop)
+	if stopped {
+		err = nil
+	}
+
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	report := rb.report
+	report.Pipeline = pipeline.Name
+	report.StartTime = start
+	report.Duration = time.Since(start)
+	report.Context = p.ectx.redact(pctx.Snapshot())
+	switch {
+	case err != nil:
+		report.StopReason = StopReasonError
+		report.Error = err.Error()
+	case stopped && rb.returned:
+		report.StopReason = StopReasonReturned
+	case stopped:
+		report.StopReason = StopReasonStopped
+	default:
+		report.StopReason = StopReasonCompleted
+	}
+	return v, &report, err
+}
+
+// RecordSkipped records that the nested pipeline at the segment below the current path was not run,
+// for execution reports. Nodes that choose between nested pipelines should call it for each one they skip.
+func (e ExecutionContext) RecordSkipped(segment string) {
+	if e.report == nil {
+		return
+	}
+	path := e.Sub(segment).path
+
+	e.report.mu.Lock()
+	defer e.report.mu.Unlock()
+	e.report.report.SkippedBranches = append(e.report.report.SkippedBranches, path)
+}
+
+// startNode adds a node to the report and returns a function that fills in how it ended.
+func (r *reportBuilder) startNode(info SpanInfo) func(result SpanResult) {
+	r.mu.Lock()
+	i := len(r.report.Nodes)
+	r.report.Nodes = append(r.report.Nodes, NodeReport{Path: info.Path, Type: info.Name, StartTime: info.StartTime})
+	r.mu.Unlock()
+
+	return func(result SpanResult) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		n := &r.report.Nodes[i]
+		n.Duration = result.Duration
+		n.Stopped = result.Stopped
+		if result.Err != nil {
+			n.Error = result.Err.Error()
+		}
+	}
+}
+
+// startCall adds a data source call to the report and returns a function that fills in how it ended.
+func (r *reportBuilder) startCall(path string, name string, params any) func(err error) {
+	start := time.Now()
+
+	r.mu.Lock()
+	i := len(r.report.DataSourceCalls)
+	r.report.DataSourceCalls = append(r.report.DataSourceCalls, DataSourceCallReport{
+		Path:      path,
+		Name:      name,
+		Params:    params,
+		StartTime: start,
+	})
+	r.mu.Unlock()
+
+	return func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		c := &r.report.DataSourceCalls[i]
+		c.Duration = time.Since(start)
+		if err != nil && !errors.Is(err, ErrPipelineExecutionStop) {
+			c.Error = err.Error()
+		}
+	}
+}
+
+func (r *reportBuilder) setReturned() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.returned = true
+}
@@ -0,0 +1,129 @@
+package pipedream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type credentials struct {
+	User     string
+	Password string
+}
+
+func TestExecuteWithReport(t *testing.T) {
+	executor := NewPipelineExecutor()
+	executor.SetLogger(nil, LogConfig{SensitiveKeys: []string{"Password"}})
+	executor.RegisterDataSource(NewDataSource("double", double))
+	executor.RegisterDataSource(NewDataSource("login", func(ctx context.Context, params credentials) (bool, error) {
+		return true, nil
+	}))
+
+	query := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		v, err := Query[int, int](ectx, "double", 3)
+		pctx.SetValue("x", v)
+		return err
+	})
+	login := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		_, err := Query[credentials, bool](ectx, "login", credentials{User: "ann", Password: "hunter2"})
+		pctx.SetValue("secret", "s")
+		pctx.MarkSensitive("secret")
+		return err
+	})
+	skip := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		ectx.RecordSkipped("FalsePipeline")
+		return nil
+	})
+	p := Pipeline{Name: "report", Nodes: []Node{setNode{"a"}, Pipeline{Nodes: []Node{query}}, skip, login}}
+
+	_, report, err := executor.ExecuteWithReport(context.Background(), p)
+	if err != nil {
+		t.Fatalf("ExecuteWithReport returned error: %v", err)
+	}
+
+	if report.ExecutionID == "" || report.Pipeline != "report" || report.StopReason != StopReasonCompleted || report.Error != "" {
+		t.Errorf("report = %+v, want an execution ID, pipeline report and completed", report)
+	}
+
+	var paths []string
+	for _, n := range report.Nodes {
+		paths = append(paths, n.Path)
+	}
+	wantPaths := []string{"nodes[0]", "nodes[1]", "nodes[1].nodes[0]", "nodes[2]", "nodes[3]"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("node paths = %v, want %v", paths, wantPaths)
+	}
+	if report.Nodes[0].Type != "pipedream.setNode" {
+		t.Errorf("node type = %q, want pipedream.setNode", report.Nodes[0].Type)
+	}
+
+	if want := []string{"nodes[2].FalsePipeline"}; !reflect.DeepEqual(report.SkippedBranches, want) {
+		t.Errorf("SkippedBranches = %v, want %v", report.SkippedBranches, want)
+	}
+
+	if len(report.DataSourceCalls) != 2 {
+		t.Fatalf("DataSourceCalls = %+v, want 2", report.DataSourceCalls)
+	}
+	if c := report.DataSourceCalls[0]; c.Path != "nodes[1].nodes[0]" || c.Name != "double" || c.Params != 3 || c.Error != "" {
+		t.Errorf("first call = %+v, want double with 3 from nodes[1].nodes[0]", c)
+	}
+	wantParams := map[string]any{"User": "ann", "Password": RedactedValue}
+	if c := report.DataSourceCalls[1]; c.Name != "login" || !reflect.DeepEqual(c.Params, wantParams) {
+		t.Errorf("second call = %+v, want login with params %v", c, wantParams)
+	}
+
+	wantContext := map[string]any{"a": true, "x": 6, "secret": RedactedValue}
+	if !reflect.DeepEqual(report.Context, wantContext) {
+		t.Errorf("Context = %v, want %v", report.Context, wantContext)
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil || !strings.Contains(string(b), `"stop_reason":"completed"`) {
+		t.Errorf("json.Marshal = %s, %v, want the stop reason as text", b, err)
+	}
+}
+
+func TestReportStopReasons(t *testing.T) {
+	stop := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		return ErrPipelineExecutionStop
+	})
+	// ret does what a ReturnNode does.
+	ret := funcNode(func(ectx ExecutionContext, pctx PipelineContext) error {
+		ectx.SetReturnValue(1)
+		return ErrPipelineExecutionStop
+	})
+
+	tests := []struct {
+		name   string
+		nodes  []Node
+		want   StopReason
+		result any
+	}{
+		{"completed", []Node{setNode{"a"}, setNode{"b"}}, StopReasonCompleted, nil},
+		{"stopped", []Node{stop, setNode{"b"}}, StopReasonStopped, nil},
+		{"returned", []Node{Pipeline{Nodes: []Node{ret}}, setNode{"b"}}, StopReasonReturned, 1},
+		{"error", []Node{failNode{}, setNode{"b"}}, StopReasonError, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, report, err := NewPipelineExecutor().ExecuteWithReport(context.Background(), Pipeline{Nodes: tt.nodes})
+			if report.StopReason != tt.want || v != tt.result {
+				t.Errorf("got %v with stop reason %v, want %v with %v", v, report.StopReason, tt.result, tt.want)
+			}
+			if (tt.want == StopReasonError) != (err != nil) {
+				t.Errorf("got error %v", err)
+			}
+			if tt.want == StopReasonError && (report.Error == "" || !errors.Is(err, errNode)) {
+				t.Errorf("report error = %q, want errNode", report.Error)
+			}
+
+			_, ranB := report.Context["b"]
+			if ranB != (tt.want == StopReasonCompleted) {
+				t.Errorf("node after the first ran: %v", ranB)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+// Package sources provides data sources backed by files, databases and HTTP services.
+// Each constructor returns a pipedream.DataSource ready to pass to RegisterDataSource.
+package sources
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sidkurella/pipedream"
+)
+
+var ErrNoKeyField = fmt.Errorf("file data source has no key field configured")
+var ErrUnsupportedFieldType = fmt.Errorf("unsupported field type for CSV decoding")
+
+// FileQuery selects rows from a file data source.
+// If Key is set, the rows whose key field equals it are selected. Otherwise rows are selected by position,
+// from Start up to but not including End. An End that is not positive selects up to the last row.
+type FileQuery struct {
+	Key string
+
+	Start int
+	End   int
+}
+
+// FileConfig configures a file data source.
+type FileConfig struct {
+	// KeyField names the struct field or map key that FileQuery.Key is matched against.
+	// Keys are compared as strings formatted with fmt.Sprint.
+	KeyField string
+
+	// ReloadOnChange reloads the file when its modification time or size changes.
+	// Otherwise it is only read once, on the first query.
+	ReloadOnChange bool
+}
+
+// NewCSVMapDataSource creates a data source over a CSV file with a header row.
+// Each row is a map from column name to value.
+func NewCSVMapDataSource(
+	name string,
+	path string,
+	config FileConfig,
+	opts ...pipedream.DataSourceOption,
+) pipedream.DataSource {
+	return newFileDataSource(name, path, config, readCSVMaps, opts)
+}
+
+// NewCSVDataSource creates a data source over a CSV file with a header row, decoding each row into a struct T.
+// Columns are matched to fields by a `csv:"name"` tag, or by field name if there is no tag.
+// Fields tagged `csv:"-"` are skipped. Fields may be strings, bools, integers or floats.
+func NewCSVDataSource[T any](
+	name string,
+	path string,
+	config FileConfig,
+	opts ...pipedream.DataSourceOption,
+) pipedream.DataSource {
+	return newFileDataSource(name, path, config, readCSVStructs[T], opts)
+}
+
+// NewJSONDataSource creates a data source over a file holding a JSON array of T.
+func NewJSONDataSource[T any](
+	name string,
+	path string,
+	config FileConfig,
+	opts ...pipedream.DataSourceOption,
+) pipedream.DataSource {
+	return newFileDataSource(name, path, config, readJSON[T], opts)
+}
+
+// NewJSONLinesDataSource creates a data source over a JSON Lines file, with one T per line.
+func NewJSONLinesDataSource[T any](
+	name string,
+	path string,
+	config FileConfig,
+	opts ...pipedream.DataSourceOption,
+) pipedream.DataSource {
+	return newFileDataSource(name, path, config, readJSONLines[T], opts)
+}
+
+func newFileDataSource[T any](
+	name string,
+	path string,
+	config FileConfig,
+	read func(r io.Reader) ([]T, error),
+	opts []pipedream.DataSourceOption,
+) pipedream.DataSource {
+	table := &fileTable[T]{
+		path:   path,
+		config: config,
+		read:   read,
+	}
+
+	// The file existing is the best indication of health available.
+	opts = append([]pipedream.DataSourceOption{
+		pipedream.WithHealthCheck(pipedream.HealthCheckFunc(func(ctx context.Context) error {
+			_, err := os.Stat(path)
+			return err
+		})),
+	}, opts...)
+	return pipedream.NewDataSource(name, table.query, opts...)
+}
+
+// fileTable lazily loads a file into memory, indexing rows by key.
+type fileTable[T any] struct {
+	path   string
+	config FileConfig
+	read   func(r io.Reader) ([]T, error)
+
+	mu      sync.Mutex
+	loaded  bool
+	modTime time.Time
+	size    int64
+	rows    []T
+	index   map[string][]int
+}
+
+func (f *fileTable[T]) query(ctx context.Context, q FileQuery) ([]T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	if q.Key != "" {
+		if f.config.KeyField == "" {
+			return nil, ErrNoKeyField
+		}
+		rows := []T{}
+		for _, i := range f.index[q.Key] {
+			rows = append(rows, f.rows[i])
+		}
+		return rows, nil
+	}
+
+	end := len(f.rows)
+	if q.End > 0 {
+		end = min(q.End, end)
+	}
+	start := min(max(q.Start, 0), end)
+	return append([]T{}, f.rows[start:end]...), nil
+}
+
+// load reads the file if it has not been read yet, or if it changed and ReloadOnChange is set. Must hold f.mu.
+func (f *fileTable[T]) load() error {
+	if f.loaded && !f.config.ReloadOnChange {
+		return nil
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if f.loaded && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := f.read(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	index, err := f.buildIndex(rows)
+	if err != nil {
+		return fmt.Errorf("failed to index %s: %w", f.path, err)
+	}
+
+	f.loaded = true
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.rows = rows
+	f.index = index
+	return nil
+}
+
+func (f *fileTable[T]) buildIndex(rows []T) (map[string][]int, error) {
+	index := map[string][]int{}
+	if f.config.KeyField == "" {
+		return index, nil
+	}
+
+	getter := pipedream.DefaultValueGetter{}
+	for i, row := range rows {
+		key, err := getter.GetValue(row, f.config.KeyField)
+		if errors.Is(err, pipedream.ErrValueNotFound) {
+			// Rows without a key can still be selected by position.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		k := fmt.Sprint(key)
+		index[k] = append(index[k], i)
+	}
+	return index, nil
+}
+
+func readCSVMaps(r io.Reader) ([]map[string]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []map[string]string{}, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readCSVStructs[T any](r io.Reader) ([]T, error) {
+	maps, err := readCSVMaps(r)
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not a struct", ErrUnsupportedFieldType, t)
+	}
+
+	rows := make([]T, 0, len(maps))
+	for i, m := range maps {
+		var row T
+		v := reflect.ValueOf(&row).Elem()
+		for j := range t.NumField() {
+			field := t.Field(j)
+			column := field.Tag.Get("csv")
+			if column == "-" || !field.IsExported() {
+				continue
+			}
+			if column == "" {
+				column = field.Name
+			}
+
+			s, ok := m[column]
+			if !ok {
+				continue
+			}
+			if err := setFieldFromString(v.Field(j), s); err != nil {
+				return nil, fmt.Errorf("row %d, column %s: %w", i+1, column, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func setFieldFromString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFieldType, v.Type())
+	}
+	return nil
+}
+
+func readJSON[T any](r io.Reader) ([]T, error) {
+	rows := []T{}
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func readJSONLines[T any](r io.Reader) ([]T, error) {
+	rows := []T{}
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var row T
+		err := dec.Decode(&row)
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", n, err)
+		}
+		rows = append(rows, row)
+	}
+}
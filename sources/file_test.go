@@ -0,0 +1,177 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sidkurella/pipedream"
+)
+
+// writeFile writes the contents to a file in a temporary directory, returning its path.
+func writeFile(t *testing.T, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const usersCSV = "id,name,age,admin\n1,ann,30,true\n2,bob,40,false\n1,amy,50,false\n"
+
+type csvUser struct {
+	ID       string `csv:"id"`
+	Name     string `csv:"name"`
+	Age      int    `csv:"age"`
+	Admin    bool   `csv:"admin"`
+	Skipped  string `csv:"-"`
+	Nickname string
+}
+
+func TestCSVMap(t *testing.T) {
+	source := NewCSVMapDataSource("users", writeFile(t, "users.csv", usersCSV), FileConfig{KeyField: "id"})
+
+	rows, err := source.Get(context.Background(), FileQuery{Key: "2"})
+	want := []map[string]string{{"id": "2", "name": "bob", "age": "40", "admin": "false"}}
+	if err != nil || !reflect.DeepEqual(rows, want) {
+		t.Errorf("Get = %v, %v, want %v, nil", rows, err, want)
+	}
+}
+
+func TestCSVStruct(t *testing.T) {
+	// Skipped is not read, and Nickname is matched by field name. Extra columns are ignored.
+	path := writeFile(t, "users.csv", "Skipped,Nickname,id,name,age,admin,extra\nx,annie,1,ann,30,true,e\n")
+	source := NewCSVDataSource[csvUser]("users", path, FileConfig{})
+
+	rows, err := source.Get(context.Background(), FileQuery{})
+	want := []csvUser{{ID: "1", Name: "ann", Age: 30, Admin: true, Nickname: "annie"}}
+	if err != nil || !reflect.DeepEqual(rows, want) {
+		t.Errorf("Get = %+v, %v, want %+v, nil", rows, err, want)
+	}
+}
+
+func TestCSVStructParseError(t *testing.T) {
+	source := NewCSVDataSource[csvUser]("users", writeFile(t, "users.csv", "id,age\n1,30\n2,old\n"), FileConfig{})
+
+	_, err := source.Get(context.Background(), FileQuery{})
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) || !strings.Contains(err.Error(), "row 2, column age") {
+		t.Errorf("got %v, want a parse error for row 2, column age", err)
+	}
+
+	bad := NewCSVDataSource[struct{ Tags []string }]("tags", writeFile(t, "tags.csv", "Tags\na\n"), FileConfig{})
+	if _, err := bad.Get(context.Background(), FileQuery{}); !errors.Is(err, ErrUnsupportedFieldType) {
+		t.Errorf("got %v, want ErrUnsupportedFieldType", err)
+	}
+}
+
+type jsonUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSON(t *testing.T) {
+	source := NewJSONDataSource[jsonUser]("users", writeFile(t, "users.json", `[{"id": 1, "name": "ann"}, {"id": 2, "name": "bob"}]`), FileConfig{KeyField: "ID"})
+
+	rows, err := source.Get(context.Background(), FileQuery{Key: "1"})
+	if want := []jsonUser{{ID: 1, Name: "ann"}}; err != nil || !reflect.DeepEqual(rows, want) {
+		t.Errorf("Get = %v, %v, want %v, nil", rows, err, want)
+	}
+	rows, err = source.Get(context.Background(), FileQuery{Key: "3"})
+	if err != nil || !reflect.DeepEqual(rows, []jsonUser{}) {
+		t.Errorf("Get with unknown key = %v, %v, want no rows", rows, err)
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	source := NewJSONLinesDataSource[jsonUser]("users", writeFile(t, "users.jsonl", "{\"id\": 1}\n{\"id\": 2}\n"), FileConfig{})
+	rows, err := source.Get(context.Background(), FileQuery{})
+	if want := []jsonUser{{ID: 1}, {ID: 2}}; err != nil || !reflect.DeepEqual(rows, want) {
+		t.Errorf("Get = %v, %v, want %v, nil", rows, err, want)
+	}
+
+	bad := NewJSONLinesDataSource[jsonUser]("users", writeFile(t, "bad.jsonl", "{\"id\": 1}\n{\"id\": \"two\"}\n"), FileConfig{})
+	if _, err := bad.Get(context.Background(), FileQuery{}); err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("got %v, want an error for record 2", err)
+	}
+}
+
+func TestFileQueryKey(t *testing.T) {
+	path := writeFile(t, "users.csv", usersCSV)
+
+	keyed := NewCSVDataSource[csvUser]("users", path, FileConfig{KeyField: "ID"})
+	rows, err := keyed.Get(context.Background(), FileQuery{Key: "1"})
+	if err != nil || len(rows.([]csvUser)) != 2 {
+		t.Errorf("Get = %v, %v, want both rows with id 1", rows, err)
+	}
+
+	unkeyed := NewCSVDataSource[csvUser]("users", path, FileConfig{})
+	if _, err := unkeyed.Get(context.Background(), FileQuery{Key: "1"}); !errors.Is(err, ErrNoKeyField) {
+		t.Errorf("got %v, want ErrNoKeyField", err)
+	}
+}
+
+func TestFileQueryRange(t *testing.T) {
+	source := NewCSVDataSource[csvUser]("users", writeFile(t, "users.csv", usersCSV), FileConfig{})
+
+	tests := []struct {
+		query FileQuery
+		want  []string
+	}{
+		{FileQuery{}, []string{"ann", "bob", "amy"}},
+		{FileQuery{Start: 1}, []string{"bob", "amy"}},
+		{FileQuery{Start: 1, End: 2}, []string{"bob"}},
+		{FileQuery{Start: -5, End: 1}, []string{"ann"}},
+		{FileQuery{End: 10}, []string{"ann", "bob", "amy"}},
+		{FileQuery{Start: 5}, []string{}},
+		{FileQuery{Start: 2, End: 1}, []string{}},
+	}
+	for _, tt := range tests {
+		rows, err := source.Get(context.Background(), tt.query)
+		if err != nil {
+			t.Errorf("Get(%+v) returned error: %v", tt.query, err)
+			continue
+		}
+		names := []string{}
+		for _, row := range rows.([]csvUser) {
+			names = append(names, row.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("Get(%+v) = %v, want %v", tt.query, names, tt.want)
+		}
+	}
+}
+
+func TestFileReloadOnChange(t *testing.T) {
+	path := writeFile(t, "users.csv", "id,name\n1,ann\n")
+	reloading := NewCSVMapDataSource("users", path, FileConfig{ReloadOnChange: true})
+	static := NewCSVMapDataSource("users", path, FileConfig{})
+
+	count := func(source pipedream.DataSource) int {
+		t.Helper()
+		rows, err := source.Get(context.Background(), FileQuery{})
+		if err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+		return len(rows.([]map[string]string))
+	}
+	count(reloading)
+	count(static)
+
+	// The size changes, so the rewrite is noticed even if the modification time does not.
+	if err := os.WriteFile(path, []byte("id,name\n1,ann\n2,bob\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(reloading); n != 2 {
+		t.Errorf("reloading source has %d rows, want 2", n)
+	}
+	if n := count(static); n != 1 {
+		t.Errorf("static source has %d rows, want the 1 read first", n)
+	}
+}
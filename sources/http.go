@@ -0,0 +1,207 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sidkurella/pipedream"
+)
+
+var ErrHTTPStatus = fmt.Errorf("unexpected HTTP status")
+
+// maxErrorBodySize bounds how much of a failed response's body is kept in an HTTPStatusError.
+const maxErrorBodySize = 64 << 10
+
+var urlPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// HTTPConfig configures an HTTP data source.
+// Keys are looked up in the params with pipedream.DefaultValueGetter and formatted with fmt.Sprint.
+type HTTPConfig struct {
+	// Client to send requests with. Default http.DefaultClient.
+	Client *http.Client
+
+	// Method of the request. Default GET.
+	Method string
+
+	// URL of the request. Placeholders like {id} are replaced with the value of that key in the params,
+	// path-escaped before the "?" and query-escaped after it.
+	URL string
+
+	// Query maps query parameter names to their keys in the params.
+	Query map[string]any
+
+	// Headers maps header names to their keys in the params.
+	Headers map[string]any
+
+	// StaticHeaders are added to every request.
+	StaticHeaders map[string]string
+
+	// BodyFromParams sends the params themselves as the JSON request body.
+	BodyFromParams bool
+
+	// BodyKey, if set, sends the value of that key in the params as the JSON request body.
+	BodyKey any
+}
+
+// HTTPStatusError is returned for responses with a non-2xx status. It matches ErrHTTPStatus with errors.Is.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+
+	// Body is the start of the response body.
+	Body []byte
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrHTTPStatus, e.Status, e.Body)
+}
+
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus
+}
+
+// NewHTTPDataSource creates a data source that builds an HTTP request from params of type P
+// and decodes the JSON response into a U. A 204 No Content response returns the zero U.
+// The request is sent with the caller's context, so cancelling it cancels the request.
+func NewHTTPDataSource[P any, U any](
+	name string,
+	config HTTPConfig,
+	opts ...pipedream.DataSourceOption,
+) pipedream.DataSource {
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Method == "" {
+		config.Method = http.MethodGet
+	}
+
+	getter := func(ctx context.Context, params P) (U, error) {
+		var zeroU U
+
+		req, err := newHTTPRequest(ctx, params, config)
+		if err != nil {
+			return zeroU, err
+		}
+
+		resp, err := config.Client.Do(req)
+		if err != nil {
+			return zeroU, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+			return zeroU, &HTTPStatusError{
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				Body:       body,
+			}
+		}
+		if resp.StatusCode == http.StatusNoContent {
+			return zeroU, nil
+		}
+
+		var result U
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return zeroU, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return result, nil
+	}
+
+	return pipedream.NewDataSource(name, getter, opts...)
+}
+
+func newHTTPRequest(ctx context.Context, params any, config HTTPConfig) (*http.Request, error) {
+	getter := pipedream.DefaultValueGetter{}
+	lookup := func(key any) (string, error) {
+		v, err := getter.GetValue(params, key)
+		if err != nil {
+			return "", fmt.Errorf("getting %v from params: %w", key, err)
+		}
+		return fmt.Sprint(v), nil
+	}
+
+	var err error
+	replace := func(template string, escape func(string) string) string {
+		return urlPlaceholder.ReplaceAllStringFunc(template, func(m string) string {
+			v, lookupErr := lookup(m[1 : len(m)-1])
+			if lookupErr != nil && err == nil {
+				err = lookupErr
+			}
+			return escape(v)
+		})
+	}
+	path, query, hasQuery := strings.Cut(config.URL, "?")
+	rawURL := replace(path, url.PathEscape)
+	if hasQuery {
+		rawURL += "?" + replace(query, url.QueryEscape)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Query) > 0 {
+		q := u.Query()
+		for name, key := range config.Query {
+			v, err := lookup(key)
+			if err != nil {
+				return nil, err
+			}
+			q.Set(name, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var body io.Reader
+	var bodyValue any
+	hasBody := false
+	switch {
+	case config.BodyFromParams:
+		bodyValue, hasBody = params, true
+	case config.BodyKey != nil:
+		bodyValue, err = getter.GetValue(params, config.BodyKey)
+		if err != nil {
+			return nil, fmt.Errorf("getting %v from params: %w", config.BodyKey, err)
+		}
+		hasBody = true
+	}
+	if hasBody {
+		data, err := json.Marshal(bodyValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, config.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if hasBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range config.StaticHeaders {
+		req.Header.Set(name, value)
+	}
+	for name, key := range config.Headers {
+		v, err := lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(name, v)
+	}
+
+	return req, nil
+}
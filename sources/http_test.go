@@ -0,0 +1,94 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type httpParams struct {
+	ID    string
+	Q     string
+	Token string
+}
+
+func TestHTTPRequest(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		io.WriteString(w, `{"name":"ann"}`)
+	}))
+	defer srv.Close()
+
+	source := NewHTTPDataSource[httpParams, map[string]any]("users", HTTPConfig{
+		Method:         http.MethodPost,
+		URL:            srv.URL + "/users/{ID}?q={Q}&v=1",
+		Query:          map[string]any{"id": "ID"},
+		Headers:        map[string]any{"Authorization": "Token"},
+		StaticHeaders:  map[string]string{"X-Client": "pipedream"},
+		BodyFromParams: true,
+	})
+
+	params := httpParams{ID: "a/b c", Q: "x&y=z", Token: "secret"}
+	resp, err := source.Get(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if want := map[string]any{"name": "ann"}; !reflect.DeepEqual(resp, want) {
+		t.Errorf("Get = %v, want %v", resp, want)
+	}
+
+	if path := got.URL.EscapedPath(); path != "/users/a%2Fb%20c" {
+		t.Errorf("path = %q, want the placeholder path-escaped", path)
+	}
+	query := got.URL.Query()
+	if query.Get("q") != "x&y=z" || query.Get("v") != "1" || query.Get("id") != "a/b c" || query.Has("y") {
+		t.Errorf("query = %v, want q=x&y=z, v=1 and id=a/b c", query)
+	}
+	if got.Header.Get("Authorization") != "secret" || got.Header.Get("X-Client") != "pipedream" {
+		t.Errorf("headers = %v, want Authorization and X-Client", got.Header)
+	}
+	var sent httpParams
+	if err := json.Unmarshal(body, &sent); err != nil || sent != params || got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("body = %s (%s), want the params as JSON", body, got.Header.Get("Content-Type"))
+	}
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such user", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	source := NewHTTPDataSource[httpParams, map[string]any]("users", HTTPConfig{URL: srv.URL + "/users/{ID}"})
+
+	_, err := source.Get(context.Background(), httpParams{ID: "1"})
+	var se *HTTPStatusError
+	if !errors.Is(err, ErrHTTPStatus) || !errors.As(err, &se) {
+		t.Fatalf("got %v, want HTTPStatusError", err)
+	}
+	if se.StatusCode != http.StatusNotFound || string(se.Body) != "no such user\n" {
+		t.Errorf("got status %d and body %q, want 404 and the response body", se.StatusCode, se.Body)
+	}
+}
+
+func TestHTTPNoContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	source := NewHTTPDataSource[httpParams, *httpParams]("users", HTTPConfig{URL: srv.URL})
+
+	resp, err := source.Get(context.Background(), httpParams{})
+	if err != nil || resp != (*httpParams)(nil) {
+		t.Errorf("Get = %v, %v, want nil, nil", resp, err)
+	}
+}
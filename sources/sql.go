@@ -0,0 +1,209 @@
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/sidkurella/pipedream"
+)
+
+// SQLConfig configures a SQL data source.
+type SQLConfig struct {
+	// Query to run. Placeholders follow the conventions of the database driver.
+	Query string
+
+	// Args are the keys of the positional arguments in the params, in order.
+	// Each is looked up in the params with pipedream.DefaultValueGetter, so a key is a struct field name,
+	// map key or slice index. For primitive params any key returns the params themselves.
+	Args []any
+
+	// NamedArgs maps named arguments, passed with sql.Named, to their keys in the params.
+	NamedArgs map[string]any
+}
+
+// NewSQLDataSource creates a data source that runs a query with arguments taken from params of type P,
+// scanning each row into a T.
+//
+// If T is a struct, columns are matched to fields by a `db:"name"` tag, or case-insensitively by field name
+// if there is no tag. Fields tagged `db:"-"` are skipped, as are columns with no matching field.
+// Otherwise the query must return a single column, which is scanned into T directly.
+func NewSQLDataSource[P any, T any](
+	name string,
+	db *sql.DB,
+	config SQLConfig,
+	opts ...pipedream.DataSourceOption,
+) pipedream.DataSource {
+	return newSQLDataSource[P](name, db, config, func(rows *sql.Rows) ([]T, error) {
+		return scanStructs[T](rows)
+	}, opts)
+}
+
+// NewSQLMapDataSource creates a data source that runs a query with arguments taken from params of type P,
+// returning each row as a map from column name to value.
+func NewSQLMapDataSource[P any](
+	name string,
+	db *sql.DB,
+	config SQLConfig,
+	opts ...pipedream.DataSourceOption,
+) pipedream.DataSource {
+	return newSQLDataSource[P](name, db, config, scanMaps, opts)
+}
+
+func newSQLDataSource[P any, T any](
+	name string,
+	db *sql.DB,
+	config SQLConfig,
+	scan func(rows *sql.Rows) ([]T, error),
+	opts []pipedream.DataSourceOption,
+) pipedream.DataSource {
+	getter := func(ctx context.Context, params P) ([]T, error) {
+		args, err := sqlArgs(params, config)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := db.QueryContext(ctx, config.Query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		result, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		return result, rows.Err()
+	}
+
+	opts = append([]pipedream.DataSourceOption{
+		pipedream.WithHealthCheck(pipedream.HealthCheckFunc(db.PingContext)),
+	}, opts...)
+	return pipedream.NewDataSource(name, getter, opts...)
+}
+
+// sqlArgs builds the query arguments from the params.
+func sqlArgs(params any, config SQLConfig) ([]any, error) {
+	getter := pipedream.DefaultValueGetter{}
+	args := make([]any, 0, len(config.Args)+len(config.NamedArgs))
+
+	for i, key := range config.Args {
+		v, err := getter.GetValue(params, key)
+		if err != nil {
+			return nil, fmt.Errorf("getting argument %d (%v) from params: %w", i+1, key, err)
+		}
+		args = append(args, v)
+	}
+
+	// Sorted so the argument order is deterministic.
+	for _, name := range slices.Sorted(maps.Keys(config.NamedArgs)) {
+		key := config.NamedArgs[name]
+		v, err := getter.GetValue(params, key)
+		if err != nil {
+			return nil, fmt.Errorf("getting argument %s (%v) from params: %w", name, key, err)
+		}
+		args = append(args, sql.Named(name, v))
+	}
+
+	return args, nil
+}
+
+func scanMaps(rows *sql.Rows) ([]map[string]any, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+func scanStructs[T any](rows *sql.Rows) ([]T, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeFor[T]()
+	var fields []int // Index of the field for each column, or -1 to discard it.
+	if t.Kind() == reflect.Struct {
+		fields = columnFields(t, columns)
+	} else if len(columns) != 1 {
+		return nil, fmt.Errorf("cannot scan %d columns into %s", len(columns), t)
+	}
+
+	result := []T{}
+	for rows.Next() {
+		var row T
+		v := reflect.ValueOf(&row).Elem()
+
+		dest := make([]any, len(columns))
+		for i := range columns {
+			switch {
+			case fields == nil:
+				dest[i] = &row
+			case fields[i] < 0:
+				dest[i] = new(any)
+			default:
+				dest[i] = v.Field(fields[i]).Addr().Interface()
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// columnFields matches each column to a field of the struct type.
+func columnFields(t reflect.Type, columns []string) []int {
+	byName := map[string]int{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("db")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		if tag != "" {
+			byName[tag] = i
+			continue
+		}
+		if _, ok := byName[strings.ToLower(field.Name)]; !ok {
+			byName[strings.ToLower(field.Name)] = i
+		}
+	}
+
+	fields := make([]int, len(columns))
+	for i, column := range columns {
+		idx, ok := byName[column]
+		if !ok {
+			idx, ok = byName[strings.ToLower(column)]
+		}
+		if !ok {
+			idx = -1
+		}
+		fields[i] = idx
+	}
+	return fields
+}
@@ -0,0 +1,155 @@
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// fakeDB is a driver.Connector whose connections record the queries they run
+// and answer each with the same columns and rows.
+type fakeDB struct {
+	columns []string
+	rows    [][]driver.Value
+
+	query string
+	args  []driver.NamedValue
+}
+
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return fakeConn{f}, nil
+}
+
+func (f *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.query = query
+	c.db.args = args
+	return &fakeRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLArgs(t *testing.T) {
+	type params struct {
+		ID   int
+		Name string
+	}
+	fake := &fakeDB{columns: []string{"n"}}
+	source := NewSQLMapDataSource[params]("users", sql.OpenDB(fake), SQLConfig{
+		Query:     "SELECT n FROM users WHERE id = ? AND name = @name AND alias = @alias",
+		Args:      []any{"ID"},
+		NamedArgs: map[string]any{"name": "Name", "alias": "Name"},
+	})
+
+	if _, err := source.Get(context.Background(), params{ID: 1, Name: "ann"}); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	want := []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Name: "alias", Value: "ann"},
+		{Ordinal: 3, Name: "name", Value: "ann"},
+	}
+	if !reflect.DeepEqual(fake.args, want) {
+		t.Errorf("args = %v, want %v", fake.args, want)
+	}
+
+	bad := NewSQLMapDataSource[params]("users", sql.OpenDB(fake), SQLConfig{Query: "SELECT 1", Args: []any{"Missing"}})
+	if _, err := bad.Get(context.Background(), params{}); err == nil {
+		t.Error("Get with a missing argument key returned no error")
+	}
+}
+
+func TestSQLStructColumns(t *testing.T) {
+	type user struct {
+		ID      int `db:"user_id"`
+		Name    string
+		Skipped string `db:"-"`
+		private string
+	}
+	fake := &fakeDB{
+		columns: []string{"user_id", "NAME", "skipped", "private", "extra"},
+		rows: [][]driver.Value{
+			{int64(1), "ann", "s", "p", "e"},
+			{int64(2), "bob", "s", "p", "e"},
+		},
+	}
+	source := NewSQLDataSource[int, user]("users", sql.OpenDB(fake), SQLConfig{Query: "SELECT * FROM users"})
+
+	resp, err := source.Get(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	want := []user{{ID: 1, Name: "ann"}, {ID: 2, Name: "bob"}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("Get = %+v, want %+v", resp, want)
+	}
+}
+
+func TestSQLScalarColumn(t *testing.T) {
+	fake := &fakeDB{columns: []string{"count"}, rows: [][]driver.Value{{int64(3)}}}
+	source := NewSQLDataSource[int, int]("count", sql.OpenDB(fake), SQLConfig{Query: "SELECT count(*) FROM users"})
+
+	resp, err := source.Get(context.Background(), 0)
+	if err != nil || !reflect.DeepEqual(resp, []int{3}) {
+		t.Errorf("Get = %v, %v, want [3], nil", resp, err)
+	}
+
+	fake.columns = []string{"a", "b"}
+	fake.rows = [][]driver.Value{{int64(1), int64(2)}}
+	if _, err := source.Get(context.Background(), 0); err == nil {
+		t.Error("Get with two columns into an int returned no error")
+	}
+}
+
+func TestSQLMapRows(t *testing.T) {
+	fake := &fakeDB{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "ann"}}}
+	source := NewSQLMapDataSource[int]("users", sql.OpenDB(fake), SQLConfig{Query: "SELECT id, name FROM users"})
+
+	resp, err := source.Get(context.Background(), 0)
+	want := []map[string]any{{"id": int64(1), "name": "ann"}}
+	if err != nil || !reflect.DeepEqual(resp, want) {
+		t.Errorf("Get = %v, %v, want %v, nil", resp, err, want)
+	}
+}
@@ -0,0 +1,128 @@
+package pipedream
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+var ErrNotAStream = fmt.Errorf("data source does not return a stream")
+
+// StreamGetter returns a lazy sequence of responses rather than materializing them all at once.
+// The sequence should stop after yielding an error.
+type StreamGetter[T any, U any] func(ctx context.Context, params T) iter.Seq2[U, error]
+
+// NewStreamDataSource creates a DataSource whose response is an iter.Seq2[U, error].
+// The getter only runs when the sequence is iterated, so it should use the context it was given for cancellation
+// rather than assume it is iterated before Get returns.
+func NewStreamDataSource[T any, U any](
+	name string,
+	getter StreamGetter[T, U],
+) DataSource {
+	d := NewDataSource(name, func(ctx context.Context, params T) (iter.Seq2[U, error], error) {
+		return getter(ctx, params), nil
+	})
+	d.collect = func(resp any, limit int) (any, error) {
+		seq, ok := resp.(iter.Seq2[U, error])
+		if !ok {
+			return nil, fmt.Errorf("%w: response is %T", ErrNotAStream, resp)
+		}
+		return CollectStream(seq, limit)
+	}
+	return d
+}
+
+// IsStream reports whether the data source was created with NewStreamDataSource.
+func (d DataSource) IsStream() bool {
+	return d.collect != nil
+}
+
+// StreamElementType returns the type of elements in the data source's stream, or nil if it is not a stream.
+func (d DataSource) StreamElementType() reflect.Type {
+	if !d.IsStream() {
+		return nil
+	}
+	// The response type is iter.Seq2[U, error], i.e. func(yield func(U, error) bool).
+	return d.responseType.In(0).In(0)
+}
+
+// Collect materializes a response from a stream data source into a []U.
+// If limit is not negative, at most limit elements are taken and the rest of the stream is not consumed.
+func (d DataSource) Collect(resp any, limit int) (any, error) {
+	if !d.IsStream() {
+		return nil, fmt.Errorf("%w: %s", ErrNotAStream, d.Name)
+	}
+	return d.collect(resp, limit)
+}
+
+// CollectStream materializes up to limit elements of a stream into a slice, returning the first error yielded.
+// A negative limit collects every element.
+func CollectStream[U any](seq iter.Seq2[U, error], limit int) ([]U, error) {
+	out := []U{}
+	if limit == 0 {
+		return out, nil
+	}
+	for v, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// PaginateCursor adapts a cursor-paginated fetch into a StreamGetter.
+// Fetching starts at the zero cursor and continues while fetch reports more pages.
+// Pages are only fetched as the stream is consumed.
+func PaginateCursor[T any, U any, C any](
+	fetch func(ctx context.Context, params T, cursor C) (page []U, next C, more bool, err error),
+) StreamGetter[T, U] {
+	return func(ctx context.Context, params T) iter.Seq2[U, error] {
+		return func(yield func(U, error) bool) {
+			var cursor C
+			for {
+				if err := ctx.Err(); err != nil {
+					var zeroU U
+					yield(zeroU, err)
+					return
+				}
+
+				page, next, more, err := fetch(ctx, params, cursor)
+				if err != nil {
+					var zeroU U
+					yield(zeroU, err)
+					return
+				}
+				for _, v := range page {
+					if !yield(v, nil) {
+						return
+					}
+				}
+				if !more {
+					return
+				}
+				cursor = next
+			}
+		}
+	}
+}
+
+// PaginateOffset adapts an offset/limit-paginated fetch into a StreamGetter.
+// Fetching stops at the first page shorter than pageSize.
+func PaginateOffset[T any, U any](
+	pageSize int,
+	fetch func(ctx context.Context, params T, offset int, limit int) ([]U, error),
+) StreamGetter[T, U] {
+	pageSize = max(pageSize, 1)
+	return PaginateCursor(func(ctx context.Context, params T, offset int) ([]U, int, bool, error) {
+		page, err := fetch(ctx, params, offset, pageSize)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return page, offset + len(page), len(page) >= pageSize, nil
+	})
+}
@@ -0,0 +1,106 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestPaginateCursorFetchesLazily(t *testing.T) {
+	var cursors []string
+	pages := map[string][]int{"": {1, 2}, "b": {3, 4}, "c": {5}}
+	next := map[string]string{"": "b", "b": "c"}
+	getter := PaginateCursor(func(ctx context.Context, params int, cursor string) ([]int, string, bool, error) {
+		cursors = append(cursors, cursor)
+		n, more := next[cursor]
+		return pages[cursor], n, more, nil
+	})
+
+	got, err := CollectStream(getter(context.Background(), 0), 3)
+	if err != nil || !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("CollectStream(3) = %v, %v, want [1 2 3], nil", got, err)
+	}
+	if !slices.Equal(cursors, []string{"", "b"}) {
+		t.Errorf("fetched cursors %q, want only the first two pages", cursors)
+	}
+
+	cursors = nil
+	got, err = CollectStream(getter(context.Background(), 0), -1)
+	if err != nil || !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("CollectStream(-1) = %v, %v, want every element", got, err)
+	}
+	if !slices.Equal(cursors, []string{"", "b", "c"}) {
+		t.Errorf("fetched cursors %q, want every page", cursors)
+	}
+}
+
+func TestPaginateCursorError(t *testing.T) {
+	getter := PaginateCursor(func(ctx context.Context, params int, cursor int) ([]int, int, bool, error) {
+		if cursor > 0 {
+			return nil, 0, false, errNode
+		}
+		return []int{1}, 1, true, nil
+	})
+	if _, err := CollectStream(getter(context.Background(), 0), -1); !errors.Is(err, errNode) {
+		t.Errorf("got %v, want errNode", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CollectStream(getter(ctx, 0), -1); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: got %v, want context.Canceled", err)
+	}
+}
+
+func TestPaginateOffset(t *testing.T) {
+	type page struct{ offset, limit int }
+	var fetched []page
+	getter := PaginateOffset(2, func(ctx context.Context, params int, offset int, limit int) ([]int, error) {
+		fetched = append(fetched, page{offset, limit})
+		var out []int
+		for i := offset; i < min(offset+limit, params); i++ {
+			out = append(out, i)
+		}
+		return out, nil
+	})
+
+	got, err := CollectStream(getter(context.Background(), 5), -1)
+	if err != nil || !slices.Equal(got, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("got %v, %v, want [0 1 2 3 4], nil", got, err)
+	}
+	// The short third page ends the stream.
+	if want := []page{{0, 2}, {2, 2}, {4, 2}}; !slices.Equal(fetched, want) {
+		t.Errorf("fetched %v, want %v", fetched, want)
+	}
+}
+
+func TestStreamDataSource(t *testing.T) {
+	source := NewStreamDataSource("numbers", func(ctx context.Context, params int) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {
+			for i := range params {
+				if !yield(i, nil) {
+					return
+				}
+			}
+		}
+	})
+	if !source.IsStream() || source.StreamElementType() != reflect.TypeFor[int]() {
+		t.Fatalf("IsStream = %v, StreamElementType = %v, want a stream of int", source.IsStream(), source.StreamElementType())
+	}
+
+	resp, err := source.Get(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	got, err := source.Collect(resp, 2)
+	if err != nil || !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("Collect = %v, %v, want [0 1], nil", got, err)
+	}
+
+	if _, err := NewDataSource("double", double).Collect(nil, -1); !errors.Is(err, ErrNotAStream) {
+		t.Errorf("Collect on a plain data source: got %v, want ErrNotAStream", err)
+	}
+}
@@ -0,0 +1,283 @@
+package pipedream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	SpanPipeline   SpanKind = iota // A whole execution.
+	SpanNode                       // A single node, including any nested pipelines it runs.
+	SpanCondition                  // A condition evaluated by a node.
+	SpanDataSource                 // A call to DataSource.Get.
+)
+
+// Returns a string representation of the kind.
+func (k SpanKind) String() string {
+	switch k {
+	case SpanPipeline:
+		return "pipeline"
+	case SpanNode:
+		return "node"
+	case SpanCondition:
+		return "condition"
+	case SpanDataSource:
+		return "data_source"
+	default:
+		return "unknown"
+	}
+}
+
+// SpanInfo describes what a span covers.
+type SpanInfo struct {
+	Kind SpanKind
+
+	// Name is the node or condition type, or the data source name.
+	Name string
+
+	// Path to the node or condition in the pipeline, e.g. "nodes[3].TruePipeline.nodes[1]".
+	// For data source spans, it is the path of the node making the call.
+	Path string
+
+	// ExecutionID identifies the execution the span belongs to. See ExecutionContext.ExecutionID.
+	ExecutionID string
+
+	StartTime time.Time
+}
+
+// Attributes returns the span info as key-value attributes, in the style of OpenTelemetry span attributes.
+func (s SpanInfo) Attributes() map[string]any {
+	return map[string]any{
+		"pipedream.kind":         s.Kind.String(),
+		"pipedream.name":         s.Name,
+		"pipedream.path":         s.Path,
+		"pipedream.execution_id": s.ExecutionID,
+	}
+}
+
+// SpanResult describes how a span ended.
+type SpanResult struct {
+	EndTime  time.Time
+	Duration time.Duration
+
+	// Err is the error the span ended with. Stopping a pipeline with ErrPipelineExecutionStop is not an error.
+	Err error
+
+	// Stopped is true if the span ended with ErrPipelineExecutionStop.
+	Stopped bool
+
+	// Size is the number of elements in a data source response that is a slice, array or map, and -1 otherwise.
+	Size int
+
+	// Result is the outcome of a condition span.
+	Result bool
+}
+
+// Tracer receives start and end events for the parts of a pipeline execution.
+//
+// Its shape follows OpenTelemetry-style tracers: StartSpan may return a derived context carrying the span,
+// which is passed to every nested span and data source call. For example, to adapt an OpenTelemetry tracer:
+//
+//	pipedream.TracerFunc(func(ctx context.Context, info pipedream.SpanInfo) (context.Context, pipedream.Span) {
+//		ctx, span := otelTracer.Start(ctx, info.Name, trace.WithTimestamp(info.StartTime))
+//		return ctx, pipedream.SpanFunc(func(r pipedream.SpanResult) {
+//			if r.Err != nil {
+//				span.RecordError(r.Err)
+//			}
+//			span.End(trace.WithTimestamp(r.EndTime))
+//		})
+//	})
+type Tracer interface {
+	StartSpan(ctx context.Context, info SpanInfo) (context.Context, Span)
+}
+
+// Span is ended exactly once, when the part of the execution it covers finishes.
+type Span interface {
+	End(result SpanResult)
+}
+
+// TracerFunc adapts a function to the Tracer interface.
+type TracerFunc func(ctx context.Context, info SpanInfo) (context.Context, Span)
+
+func (f TracerFunc) StartSpan(ctx context.Context, info SpanInfo) (context.Context, Span) {
+	return f(ctx, info)
+}
+
+// SpanFunc adapts a function to the Span interface.
+type SpanFunc func(result SpanResult)
+
+func (f SpanFunc) End(result SpanResult) {
+	f(result)
+}
+
+// startSpan starts a span if the execution has a tracer, logs it if the execution has a logger,
+// and reports its metrics if the execution has a MetricsSink.
+// The returned ExecutionContext carries the span's context.
+// The returned function ends the span; it must be called exactly once.
+func (e ExecutionContext) startSpan(kind SpanKind, name string) (ExecutionContext, func(result SpanResult)) {
+	return e.startSpanWith(kind, name, nil)
+}
+
+// startNodeSpan starts a span for a node, so the node's log can include the pipeline context.
+func (e ExecutionContext) startNodeSpan(name string, pctx PipelineContext) (ExecutionContext, func(result SpanResult)) {
+	return e.startSpanWith(SpanNode, name, &pctx)
+}
+
+func (e ExecutionContext) startSpanWith(kind SpanKind, name string, pctx *PipelineContext) (ExecutionContext, func(result SpanResult)) {
+	if e.tracer == nil && e.logger == nil && e.metrics == nil && e.report == nil {
+		return e, func(SpanResult) {}
+	}
+
+	start := time.Now()
+	info := SpanInfo{
+		Kind:        kind,
+		Name:        name,
+		Path:        e.path,
+		ExecutionID: e.executionID,
+		StartTime:   start,
+	}
+	span := Span(SpanFunc(func(SpanResult) {}))
+	if e.tracer != nil {
+		var ctx context.Context
+		ctx, span = e.tracer.StartSpan(e.Context(), info)
+		e.ctx = ctx
+	}
+	e.logSpanStart(info)
+	endReport := func(SpanResult) {}
+	if e.report != nil && kind == SpanNode {
+		endReport = e.report.startNode(info)
+	}
+
+	return e, func(result SpanResult) {
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(start)
+		if errors.Is(result.Err, ErrPipelineExecutionStop) {
+			result.Err = nil
+			result.Stopped = true
+		}
+		span.End(result)
+		e.logSpanEnd(info, result, pctx)
+		e.recordSpanMetrics(info, result)
+		endReport(result)
+	}
+}
+
+// traceDataSource wraps the data source so its calls are traced, logged, measured and reported,
+// if the execution observes them.
+func (e ExecutionContext) traceDataSource(d DataSource) DataSource {
+	if e.tracer == nil && e.logger == nil && e.metrics == nil && e.report == nil {
+		return d
+	}
+
+	next := d.call
+	d.call = func(ctx context.Context, params any) (any, error) {
+		endReport := func(error) {}
+		if e.report != nil {
+			endReport = e.report.startCall(e.path, d.Name, e.redactParams(params))
+		}
+		sctx, end := e.withContext(ctx).startSpan(SpanDataSource, d.Name)
+		resp, err := next(sctx.Context(), params)
+		end(SpanResult{Err: err, Size: sizeOf(resp)})
+		endReport(err)
+		return resp, err
+	}
+	return d
+}
+
+// sizeOf returns the number of elements in a slice, array or map, and -1 for anything else.
+func sizeOf(v any) int {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return rv.Len()
+	default:
+		return -1
+	}
+}
+
+// typeName returns the name of the type of v, without any pointer.
+func typeName(v any) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
+
+// TraceRecorder is a Tracer that keeps every span in memory.
+type TraceRecorder struct {
+	mu     sync.Mutex
+	spans  []RecordedSpan
+	index  map[int]int // Span ID to index in spans.
+	nextID int
+}
+
+// RecordedSpan is a span captured by a TraceRecorder.
+type RecordedSpan struct {
+	ID int
+
+	// ParentID is the ID of the enclosing span, or 0 for a root span.
+	ParentID int
+
+	Info   SpanInfo
+	Result SpanResult
+
+	// Ended is false while the span is still running.
+	Ended bool
+}
+
+type recordedSpanKey struct{}
+
+func NewTraceRecorder() *TraceRecorder {
+	return &TraceRecorder{index: map[int]int{}}
+}
+
+// StartSpan implements the Tracer interface for TraceRecorder.
+func (r *TraceRecorder) StartSpan(ctx context.Context, info SpanInfo) (context.Context, Span) {
+	parentID, _ := ctx.Value(recordedSpanKey{}).(int)
+
+	r.mu.Lock()
+	r.nextID++
+	id := r.nextID
+	r.index[id] = len(r.spans)
+	r.spans = append(r.spans, RecordedSpan{ID: id, ParentID: parentID, Info: info})
+	r.mu.Unlock()
+
+	return context.WithValue(ctx, recordedSpanKey{}, id), SpanFunc(func(result SpanResult) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// Spans started before a Reset are no longer recorded.
+		idx, ok := r.index[id]
+		if !ok {
+			return
+		}
+		r.spans[idx].Result = result
+		r.spans[idx].Ended = true
+	})
+}
+
+// Spans returns every span recorded so far, in the order they started.
+func (r *TraceRecorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RecordedSpan{}, r.spans...)
+}
+
+// Reset discards every recorded span. Spans that are still open are discarded when they end.
+func (r *TraceRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = nil
+	r.index = map[int]int{}
+}
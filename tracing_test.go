@@ -0,0 +1,56 @@
+package pipedream
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTraceRecorder(t *testing.T) {
+	recorder := NewTraceRecorder()
+	executor := NewPipelineExecutor()
+	executor.SetTracer(recorder)
+
+	p := Pipeline{Nodes: []Node{setNode{"a"}, Pipeline{Nodes: []Node{setNode{"b"}}}}}
+	if _, err := executor.Execute(context.Background(), p); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	spans := recorder.Spans()
+	want := []struct {
+		kind   SpanKind
+		path   string
+		parent int
+	}{
+		{SpanPipeline, "", 0},
+		{SpanNode, "nodes[0]", 1},
+		{SpanNode, "nodes[1]", 1},
+		{SpanNode, "nodes[1].nodes[0]", 3},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("got %d spans, want %d: %+v", len(spans), len(want), spans)
+	}
+	for i, w := range want {
+		s := spans[i]
+		if s.Info.Kind != w.kind || s.Info.Path != w.path || s.ParentID != w.parent || !s.Ended {
+			t.Errorf("span %d = %+v, want kind %v, path %q, parent %d, ended", i, s, w.kind, w.path, w.parent)
+		}
+	}
+}
+
+func TestTraceRecorderResetWithOpenSpan(t *testing.T) {
+	recorder := NewTraceRecorder()
+
+	_, open := recorder.StartSpan(context.Background(), SpanInfo{Kind: SpanNode})
+	recorder.Reset()
+	_, next := recorder.StartSpan(context.Background(), SpanInfo{Kind: SpanPipeline})
+
+	open.End(SpanResult{})
+	if spans := recorder.Spans(); len(spans) != 1 || spans[0].Ended {
+		t.Errorf("ending a span started before Reset changed the recorder: %+v", spans)
+	}
+
+	next.End(SpanResult{})
+	if spans := recorder.Spans(); len(spans) != 1 || !spans[0].Ended {
+		t.Errorf("span started after Reset did not end: %+v", spans)
+	}
+}
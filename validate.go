@@ -0,0 +1,228 @@
+package pipedream
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Diagnostic describes a single problem found while validating a pipeline.
+type Diagnostic struct {
+	// Path to the offending element, e.g. "nodes[3].TruePipeline.nodes[1].Condition".
+	Path string
+
+	// Err describes the problem. It usually wraps one of the package's sentinel errors.
+	Err error
+}
+
+func (d Diagnostic) Error() string {
+	if d.Path == "" {
+		return d.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", d.Path, d.Err)
+}
+
+func (d Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// Validator is implemented by nodes, conditions and value builders that can check their own configuration
+// before the pipeline is executed. Anything that does not implement it is assumed to be valid.
+type Validator interface {
+	Validate(vctx ValidationContext)
+}
+
+// TypedValueBuilder is implemented by value builders whose output type can be determined before execution.
+type TypedValueBuilder interface {
+	ValueBuilder
+
+	// BuildType returns the type of the value Build will produce, or nil if it cannot be determined statically.
+	BuildType(vctx ValidationContext) (reflect.Type, error)
+}
+
+// ValidationContext is passed to Validators while walking a pipeline.
+// It tracks the current path, the data sources available and which context keys have been written so far,
+// along with the type of each key where it is known.
+type ValidationContext struct {
+	dataSources map[string]DataSource
+	path        string
+	keys        map[string]reflect.Type
+	stops       *bool
+	diagnostics *[]Diagnostic
+}
+
+func newValidationContext(dataSources map[string]DataSource) ValidationContext {
+	return ValidationContext{
+		dataSources: dataSources,
+		keys:        map[string]reflect.Type{},
+		stops:       new(bool),
+		diagnostics: &[]Diagnostic{},
+	}
+}
+
+// Path returns the path of the element currently being validated.
+func (v ValidationContext) Path() string {
+	return v.path
+}
+
+// Sub returns a ValidationContext for a child element. The segment is appended to the current path.
+// The child shares written keys with its parent.
+func (v ValidationContext) Sub(segment string) ValidationContext {
+	if v.path != "" {
+		segment = v.path + "." + segment
+	}
+	v.path = segment
+	return v
+}
+
+// Clone returns a copy of the ValidationContext whose written keys are independent of the original,
+// mirroring PipelineContext.Clone. Whether the clone always stops is also tracked independently.
+func (v ValidationContext) Clone() ValidationContext {
+	v.keys = maps.Clone(v.keys)
+	v.stops = new(bool)
+	return v
+}
+
+// MarkStops records that execution never continues past the current node, e.g. because it always returns.
+func (v ValidationContext) MarkStops() {
+	*v.stops = true
+}
+
+// Stops reports whether a node validated so far always stops execution,
+// so that nothing after it in the pipeline runs.
+func (v ValidationContext) Stops() bool {
+	return *v.stops
+}
+
+// Report records a problem at the current path.
+func (v ValidationContext) Report(err error) {
+	*v.diagnostics = append(*v.diagnostics, Diagnostic{Path: v.path, Err: err})
+}
+
+func (v ValidationContext) GetDataSource(name string) (DataSource, error) {
+	dataSource, ok := v.dataSources[name]
+	if !ok {
+		return DataSource{}, ErrDataSourceNotFound
+	}
+
+	return dataSource, nil
+}
+
+// DeclareKey records that the context key will have been written by the time later nodes run.
+// The type of the value is unknown.
+func (v ValidationContext) DeclareKey(k string) {
+	v.keys[k] = nil
+}
+
+// DeclareKeyType records that the context key will have been written with a value of the given type.
+// A nil or interface type means the type of the value is unknown.
+func (v ValidationContext) DeclareKeyType(k string, t reflect.Type) {
+	v.keys[k] = staticType(t)
+}
+
+// HasKey reports whether an earlier node writes the context key.
+func (v ValidationContext) HasKey(k string) bool {
+	_, ok := v.keys[k]
+	return ok
+}
+
+// KeyType returns the type of the value written to the context key.
+// The type is nil if the key is not written or its type is unknown.
+func (v ValidationContext) KeyType(k string) reflect.Type {
+	return v.keys[k]
+}
+
+// TypeOf returns the type of the value the builder will produce, or nil if it cannot be determined statically.
+// Errors are ignored here; they are reported when the builder itself is validated.
+func (v ValidationContext) TypeOf(b ValueBuilder) reflect.Type {
+	typed, ok := b.(TypedValueBuilder)
+	if !ok {
+		return nil
+	}
+	t, err := typed.BuildType(v)
+	if err != nil {
+		return nil
+	}
+	return staticType(t)
+}
+
+// Keys returns all context keys written so far, sorted.
+func (v ValidationContext) Keys() []string {
+	return slices.Sorted(maps.Keys(v.keys))
+}
+
+// MergeBranches declares every key that was written in all of the given branches.
+// Use this after validating mutually exclusive sub-pipelines that share the parent's context.
+// Branches that always stop are left out, since later nodes only run after the other branches.
+// A key keeps its type only if every branch agrees on it.
+// If every branch stops, the current context is marked as stopping too.
+func (v ValidationContext) MergeBranches(branches ...ValidationContext) {
+	branches = slices.DeleteFunc(slices.Clone(branches), ValidationContext.Stops)
+	if len(branches) == 0 {
+		v.MarkStops()
+		return
+	}
+	for k, t := range branches[0].keys {
+		inAll := true
+		for _, b := range branches[1:] {
+			if !b.HasKey(k) {
+				inAll = false
+				break
+			}
+			if b.KeyType(k) != t {
+				t = nil
+			}
+		}
+		if inAll {
+			v.DeclareKeyType(k, t)
+		}
+	}
+}
+
+// ValidatePipeline validates every node in the pipeline in order.
+func (v ValidationContext) ValidatePipeline(p Pipeline) {
+	for i, node := range p.Nodes {
+		nv := v.Sub(fmt.Sprintf("nodes[%d]", i))
+		if node == nil {
+			nv.Report(ErrNilNode)
+			continue
+		}
+		if validator, ok := node.(Validator); ok {
+			validator.Validate(nv)
+		}
+		_, own := innerNode[ErrorPolicyNode](node)
+		p.errorPolicyFor(node).validate(nv, node, own)
+	}
+}
+
+// ValidateCondition validates a condition, reporting an error if it is nil.
+func (v ValidationContext) ValidateCondition(c Condition) {
+	if c == nil {
+		v.Report(ErrNilCondition)
+		return
+	}
+	if validator, ok := c.(Validator); ok {
+		validator.Validate(v)
+	}
+}
+
+// ValidateValue validates a value builder, reporting an error if it is nil.
+func (v ValidationContext) ValidateValue(b ValueBuilder) {
+	if b == nil {
+		v.Report(ErrNilValueBuilder)
+		return
+	}
+	if validator, ok := b.(Validator); ok {
+		validator.Validate(v)
+	}
+}
+
+// staticType normalizes a type for static checking.
+// Values of interface type carry their dynamic type at runtime, so nothing is known about them statically.
+func staticType(t reflect.Type) reflect.Type {
+	if t == nil || t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
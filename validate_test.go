@@ -0,0 +1,218 @@
+package pipedream
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type user struct {
+	Name string
+	Age  int
+	Tags []string
+
+	password string
+}
+
+// declareNode declares the key with the type of its value during validation, like a node saving a result would.
+type declareNode struct {
+	key   string
+	value any
+}
+
+func (d declareNode) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	pctx.SetValue(d.key, d.value)
+	return nil
+}
+
+func (d declareNode) Validate(vctx ValidationContext) {
+	vctx.DeclareKeyType(d.key, reflect.TypeOf(d.value))
+}
+
+// conditionNode validates its condition, like a node evaluating one would.
+type conditionNode struct {
+	Condition Condition
+}
+
+func (c conditionNode) Execute(ectx ExecutionContext, pctx PipelineContext) error {
+	_, err := ectx.EvaluateCondition(c.Condition, pctx)
+	return err
+}
+
+func (c conditionNode) Validate(vctx ValidationContext) {
+	vctx.Sub("Condition").ValidateCondition(c.Condition)
+}
+
+func field(key string, k any) *DynamicValue {
+	return &DynamicValue{ContextKey: key, Getter: DefaultValueGetter{}, Key: k}
+}
+
+func compare(lhs, rhs ValueBuilder, op ConditionOperand) conditionNode {
+	return conditionNode{&ValueCondition{LHS: lhs, RHS: rhs, Operand: op}}
+}
+
+func TestCheckCompareTypes(t *testing.T) {
+	type pair struct{ A, B int }
+	tests := []struct {
+		lhs, rhs reflect.Type
+		op       ConditionOperand
+		want     error
+	}{
+		{reflect.TypeFor[string](), reflect.TypeFor[int](), ConditionEqual, ErrIncompatibleTypes},
+		{reflect.TypeFor[string](), reflect.TypeFor[string](), ConditionLessThan, nil},
+		{reflect.TypeFor[int](), reflect.TypeFor[float64](), ConditionLessThan, nil},
+		{reflect.TypeFor[int8](), reflect.TypeFor[uint64](), ConditionEqual, nil},
+		{reflect.TypeFor[bool](), reflect.TypeFor[bool](), ConditionEqual, nil},
+		{reflect.TypeFor[bool](), reflect.TypeFor[bool](), ConditionGreaterThan, ErrOperationNotSupported},
+		{reflect.TypeFor[pair](), reflect.TypeFor[pair](), ConditionNotEqual, nil},
+		{reflect.TypeFor[pair](), reflect.TypeFor[pair](), ConditionLessThan, ErrOperationNotSupported},
+		{reflect.TypeFor[*int](), reflect.TypeFor[string](), ConditionEqual, nil},
+		{reflect.TypeFor[*int](), reflect.TypeFor[string](), ConditionLessThan, ErrIncompatibleTypes},
+		{nil, reflect.TypeFor[string](), ConditionLessThan, nil},
+	}
+	for _, tt := range tests {
+		err := checkCompareTypes(tt.lhs, tt.rhs, tt.op)
+		if !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+			t.Errorf("checkCompareTypes(%v, %v, %v) = %v, want %v", tt.lhs, tt.rhs, tt.op, err, tt.want)
+		}
+	}
+}
+
+func TestGetType(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   reflect.Type
+		key     any
+		want    reflect.Type
+		wantErr error
+	}{
+		{"struct field", reflect.TypeFor[user](), "Name", reflect.TypeFor[string](), nil},
+		{"pointer to struct field", reflect.TypeFor[*user](), "Age", reflect.TypeFor[int](), nil},
+		{"missing field", reflect.TypeFor[user](), "Email", nil, ErrValueNotFound},
+		{"unexported field", reflect.TypeFor[user](), "password", nil, ErrFieldIsUnexported},
+		{"field with float key", reflect.TypeFor[user](), 1.5, nil, ErrKeyTypeInvalid},
+		{"slice element", reflect.TypeFor[[]user](), 5, reflect.TypeFor[user](), nil},
+		{"array element", reflect.TypeFor[[2]int](), 1, reflect.TypeFor[int](), nil},
+		{"array out of range", reflect.TypeFor[[2]int](), 2, nil, ErrValueNotFound},
+		{"map element", reflect.TypeFor[map[string]float64](), "k", reflect.TypeFor[float64](), nil},
+		{"map with wrong key type", reflect.TypeFor[map[string]float64](), 1.5, nil, ErrKeyTypeInvalid},
+		{"interface element", reflect.TypeFor[[]any](), 0, nil, nil},
+		{"interface", reflect.TypeFor[any](), "Name", nil, nil},
+		{"primitive", reflect.TypeFor[int](), "anything", reflect.TypeFor[int](), nil},
+		{"func", reflect.TypeFor[func()](), "Name", nil, ErrImproperValueKind},
+		{"nil key", reflect.TypeFor[user](), nil, nil, ErrKeyIsEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DefaultValueGetter{}.GetType(tt.input, tt.key)
+			if got != tt.want || !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("GetType = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateComparisonTypes(t *testing.T) {
+	declare := declareNode{"user", user{}}
+	tests := []struct {
+		name string
+		node Node
+		want error
+	}{
+		{"string field against int literal", compare(field("user", "Name"), LiteralValue[int]{Value: 5}, ConditionEqual), ErrIncompatibleTypes},
+		{"int field against float literal", compare(field("user", "Age"), LiteralValue[float64]{Value: 5}, ConditionLessThan), nil},
+		{"slice element against string literal", compare(
+			&DynamicValue{ContextKey: "tags", Getter: DefaultValueGetter{}, Key: 0},
+			LiteralValue[string]{Value: "a"},
+			ConditionEqual,
+		), nil},
+		{"slice element against int literal", compare(
+			&DynamicValue{ContextKey: "tags", Getter: DefaultValueGetter{}, Key: 0},
+			LiteralValue[int]{Value: 1},
+			ConditionEqual,
+		), ErrIncompatibleTypes},
+		{"whole struct ordered", compare(&DynamicValue{ContextKey: "user"}, &DynamicValue{ContextKey: "user"}, ConditionLessThan), ErrOperationNotSupported},
+		{"missing field", compare(field("user", "Email"), LiteralValue[string]{Value: "a"}, ConditionEqual), ErrValueNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Pipeline{Nodes: []Node{declare, declareNode{"tags", []string{}}, tt.node}}
+			diags := NewPipelineExecutor().Validate(p)
+			if tt.want == nil {
+				if len(diags) != 0 {
+					t.Errorf("Validate = %v, want no diagnostics", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || !errors.Is(diags[0], tt.want) {
+				t.Errorf("Validate = %v, want %v", diags, tt.want)
+			}
+		})
+	}
+}
+
+type wantDiagnostic struct {
+	path string
+	err  error
+}
+
+// checkDiagnostics checks that exactly the wanted problems were reported, in order.
+func checkDiagnostics(t *testing.T, diags []Diagnostic, want []wantDiagnostic) {
+	t.Helper()
+	if len(diags) != len(want) {
+		t.Fatalf("Validate = %v, want %d diagnostics", diags, len(want))
+	}
+	for i, w := range want {
+		if diags[i].Path != w.path || !errors.Is(diags[i], w.err) {
+			t.Errorf("diagnostic %d = %v, want %v at %s", i, diags[i], w.err, w.path)
+		}
+	}
+}
+
+func TestValidateInvalidOperand(t *testing.T) {
+	one := LiteralValue[int]{Value: 1}
+	p := Pipeline{Nodes: []Node{
+		compare(one, one, ConditionOperandInvalid),
+		compare(one, one, ConditionEqual),
+		conditionNode{&ValueCondition{LHS: one, RHS: one}},
+	}}
+
+	checkDiagnostics(t, NewPipelineExecutor().Validate(p), []wantDiagnostic{
+		{"nodes[0].Condition.Operand", ErrInvalidCondition},
+		{"nodes[2].Condition.Operand", ErrInvalidCondition},
+	})
+}
+
+func TestValidateNilConditionInAnd(t *testing.T) {
+	one := LiteralValue[int]{Value: 1}
+	p := Pipeline{Nodes: []Node{
+		conditionNode{&AndCondition{Conditions: []Condition{
+			&ValueCondition{LHS: one, RHS: one, Operand: ConditionEqual},
+			nil,
+			&OrCondition{Conditions: []Condition{nil}},
+		}}},
+		conditionNode{&AndCondition{Conditions: []Condition{nil}}},
+	}}
+
+	checkDiagnostics(t, NewPipelineExecutor().Validate(p), []wantDiagnostic{
+		{"nodes[0].Condition.Conditions[1]", ErrNilCondition},
+		{"nodes[0].Condition.Conditions[2].Conditions[0]", ErrNilCondition},
+		{"nodes[1].Condition.Conditions[0]", ErrNilCondition},
+	})
+}
+
+func TestValidateUnwrittenKey(t *testing.T) {
+	p := Pipeline{Nodes: []Node{
+		compare(&DynamicValue{ContextKey: "a"}, &DynamicValue{ContextKey: "b"}, ConditionEqual),
+		declareNode{"a", 1},
+		compare(&DynamicValue{ContextKey: "a"}, field("c", "Name"), ConditionEqual),
+		Pipeline{Nodes: []Node{compare(&DynamicValue{ContextKey: "d"}, &DynamicValue{ContextKey: "a"}, ConditionEqual)}},
+	}}
+
+	checkDiagnostics(t, NewPipelineExecutor().Validate(p), []wantDiagnostic{
+		{"nodes[0].Condition.LHS.ContextKey", ErrValueNotFoundInContext},
+		{"nodes[0].Condition.RHS.ContextKey", ErrValueNotFoundInContext},
+		{"nodes[2].Condition.RHS.ContextKey", ErrValueNotFoundInContext},
+		{"nodes[3].nodes[0].Condition.LHS.ContextKey", ErrValueNotFoundInContext},
+	})
+}
@@ -1,9 +1,13 @@
 package pipedream
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 var ErrNoContextKeyProvided = fmt.Errorf("no context key provided")
 var ErrValueNotFoundInContext = fmt.Errorf("value not found in context")
+var ErrNilValueBuilder = fmt.Errorf("nil value builder provided")
 
 // ValueBuilder builds a concrete value.
 // For more complex cases you may need to write your own.
@@ -20,6 +24,12 @@ func (l LiteralValue[T]) Build(pctx PipelineContext) (any, error) {
 	return l.Value, nil
 }
 
+// BuildType implements the TypedValueBuilder interface for LiteralValue.
+// The dynamic type of the value is used, so LiteralValue[any] is typed as well.
+func (l LiteralValue[T]) BuildType(vctx ValidationContext) (reflect.Type, error) {
+	return reflect.TypeOf(l.Value), nil
+}
+
 // DynamicValue uses a ValueGetter to extract a value from the context.
 type DynamicValue struct {
 	ContextKey string      // Key for the value to use from the context.
@@ -49,3 +59,36 @@ func (dv DynamicValue) Build(pctx PipelineContext) (any, error) {
 	// Otherwise, return the raw value directly
 	return rawValue, nil
 }
+
+// Validate implements the Validator interface for DynamicValue.
+// It reports an error if no earlier node writes ContextKey.
+func (dv DynamicValue) Validate(vctx ValidationContext) {
+	if dv.ContextKey == "" {
+		vctx.Sub("ContextKey").Report(ErrNoContextKeyProvided)
+		return
+	}
+	if !vctx.HasKey(dv.ContextKey) {
+		vctx.Sub("ContextKey").Report(fmt.Errorf("%w: no earlier node writes %q", ErrValueNotFoundInContext, dv.ContextKey))
+		return
+	}
+	if _, err := dv.BuildType(vctx); err != nil {
+		vctx.Sub("Key").Report(fmt.Errorf("getting %v from %q: %w", dv.Key, dv.ContextKey, err))
+	}
+}
+
+// BuildType implements the TypedValueBuilder interface for DynamicValue.
+// The type is known if the type of the context value is known and the Getter is nil or a TypedValueGetter.
+func (dv DynamicValue) BuildType(vctx ValidationContext) (reflect.Type, error) {
+	inputType := vctx.KeyType(dv.ContextKey)
+	if inputType == nil {
+		return nil, nil
+	}
+
+	if dv.Getter == nil {
+		return inputType, nil
+	}
+	if typed, ok := dv.Getter.(TypedValueGetter); ok {
+		return typed.GetType(inputType, dv.Key)
+	}
+	return nil, nil
+}
@@ -16,6 +16,14 @@ type ValueGetter interface {
 	GetValue(input any, valueKey any) (any, error)
 }
 
+// TypedValueGetter is implemented by value getters that can resolve the type of the value they would get
+// from an input of the given type, without needing the input itself.
+// A nil type means the type cannot be determined statically.
+type TypedValueGetter interface {
+	ValueGetter
+	GetType(inputType reflect.Type, valueKey any) (reflect.Type, error)
+}
+
 // If the input is a struct or pointer to a struct, uses reflection to get the field with the name specified.
 // If the input is a map, gets the value for the key provided.
 // If the input is an array or slice, gets the value at the specified index.
@@ -104,4 +112,71 @@ func getValueFromReflectValue(v reflect.Value, key reflect.Value) (any, error) {
 	}
 }
 
+// GetType implements the TypedValueGetter interface for DefaultValueGetter.
+// It follows the same rules as GetValue, using field and element types instead of values.
+func (d DefaultValueGetter) GetType(inputType reflect.Type, valueKey any) (reflect.Type, error) {
+	if inputType == nil {
+		return nil, nil
+	}
+
+	if valueKey == nil {
+		return nil, ErrKeyIsEmpty
+	}
+	keyType := reflect.TypeOf(valueKey)
+
+	return getTypeFromReflectType(inputType, keyType, reflect.ValueOf(valueKey))
+}
+
+func getTypeFromReflectType(t reflect.Type, keyType reflect.Type, key reflect.Value) (reflect.Type, error) {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		// This is a primitive. The value itself is returned.
+		return t, nil
+	case reflect.Array, reflect.Slice:
+		indexType := reflect.TypeFor[int]()
+		if !keyType.ConvertibleTo(indexType) {
+			return nil, ErrKeyTypeInvalid
+		}
+		if t.Kind() == reflect.Array {
+			// Array lengths are part of the type, so out of range indices can be caught early.
+			i := key.Convert(indexType).Interface().(int)
+			if i < 0 || i >= t.Len() {
+				return nil, ErrValueNotFound
+			}
+		}
+		return staticType(t.Elem()), nil
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return nil, ErrImproperValueKind
+	case reflect.Pointer:
+		return getTypeFromReflectType(t.Elem(), keyType, key)
+	case reflect.Interface:
+		// The dynamic type is only known at runtime.
+		return nil, nil
+	case reflect.Map:
+		if !keyType.ConvertibleTo(t.Key()) {
+			return nil, ErrKeyTypeInvalid
+		}
+		return staticType(t.Elem()), nil
+	case reflect.Struct:
+		stringType := reflect.TypeFor[string]()
+		if !keyType.ConvertibleTo(stringType) {
+			return nil, ErrKeyTypeInvalid
+		}
+		fieldName := key.Convert(stringType).Interface().(string)
+
+		field, ok := t.FieldByName(fieldName)
+		if !ok {
+			return nil, ErrValueNotFound
+		}
+		if !field.IsExported() {
+			return nil, ErrFieldIsUnexported
+		}
+		return staticType(field.Type), nil
+	default:
+		return nil, ErrImproperValueKind
+	}
+}
+
 // TODO: Chainable value getter?